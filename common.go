@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/alexxyjiang/domain-list-custom/lib"
+	"github.com/alexxyjiang/domain-list-custom/plugin/v2ray"
+	"github.com/spf13/cobra"
+)
+
+// newInstance creates an instance from the config file, honoring the keep-going flag
+func newInstance(configFile string) (*lib.Instance, error) {
+	slog.Debug("loading config from", "config", configFile)
+
+	instance, err := lib.NewInstance()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new instance: %w", err)
+	}
+	instance.KeepGoing = keepGoing
+
+	if err := instance.InitConfig(configFile); err != nil {
+		return nil, fmt.Errorf("failed to initial config: %w", err)
+	}
+
+	return instance, nil
+}
+
+// loadInstance creates an instance from the config file and processes its inputs and transforms.
+// In keep-going mode, the instance is returned along with the errors of the failed plugins.
+func loadInstance(configFile string) (*lib.Instance, error) {
+	instance, err := newInstance(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	inputErr := instance.RunInput()
+	if inputErr != nil && !instance.KeepGoing {
+		return nil, inputErr
+	}
+
+	transformErr := instance.RunTransform()
+	if transformErr != nil && !instance.KeepGoing {
+		return nil, transformErr
+	}
+
+	return instance, errors.Join(inputErr, transformErr)
+}
+
+// loadGeoSiteContainer reads the lists of a V2Ray geosite file into a container
+func loadGeoSiteContainer(datFile string) (lib.Container, error) {
+	slog.Debug("loading geosite file", "dat", datFile)
+
+	geositeList, err := v2ray.ReadGeoSiteList(datFile)
+	if err != nil {
+		return nil, &lib.StageError{Stage: lib.StageInput, Err: err}
+	}
+
+	container, err := v2ray.NewContainerFromGeoSiteList(geositeList)
+	if err != nil {
+		return nil, &lib.StageError{Stage: lib.StageInput, Err: err}
+	}
+	return container, nil
+}
+
+// loadContainer loads the domain lists from the geosite file of the dat flag if specified,
+// otherwise from the inputs of the config file. As with loadInstance, a container may be
+// returned along with an error in keep-going mode.
+func loadContainer(cmd *cobra.Command) (lib.Container, error) {
+	if datFile, _ := cmd.Flags().GetString("dat"); datFile != "" {
+		return loadGeoSiteContainer(datFile)
+	}
+
+	configFile, _ := cmd.Flags().GetString("config")
+	instance, err := loadInstance(configFile)
+	if instance == nil {
+		return nil, err
+	}
+	return instance.Container, err
+}
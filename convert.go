@@ -3,7 +3,6 @@ package main
 import (
 	"log/slog"
 
-	"github.com/alexxyjiang/domain-list-custom/lib"
 	"github.com/spf13/cobra"
 )
 
@@ -16,22 +15,17 @@ var convertCmd = &cobra.Command{
 	Use:     "convert",
 	Aliases: []string{"conv"},
 	Short:   "Convert domain list data from one format to another by using config file",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		configFile, _ := cmd.Flags().GetString("config")
-		slog.Debug("loading config from", "config", configFile)
-
-		instance, err := lib.NewInstance()
+		instance, err := newInstance(configFile)
 		if err != nil {
-			slog.Error("failed to create new instance", "err", err)
-		}
-
-		if err := instance.InitConfig(configFile); err != nil {
-			slog.Error("failed to initial config", "err", err)
+			return err
 		}
 
 		if err := instance.Run(); err != nil {
-			slog.Error("failed to convert", "err", err)
+			return err
 		}
 		slog.Info("convert success")
+		return nil
 	},
 }
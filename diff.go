@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/alexxyjiang/domain-list-custom/lib"
+	"github.com/alexxyjiang/domain-list-custom/plugin/v2ray"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+	diffCmd.PersistentFlags().StringP("config", "c", "config.json", "URI of the JSON format config file, used when NEW_DAT is not specified")
+	diffCmd.PersistentFlags().StringP("format", "f", "text", "Output format of the difference, text or json")
+	diffCmd.PersistentFlags().Bool("exit-code", false, "Exit with code 1 if there are differences, as with git diff")
+}
+
+// errDifferent is returned by the diff command in exit code mode when there are differences
+var errDifferent = errors.New("differences found")
+
+var diffCmd = &cobra.Command{
+	Use:   "diff OLD_DAT [NEW_DAT]",
+	Short: "Compare two V2Ray geosite files, or a geosite file with the result of the config file",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		format = strings.ToLower(strings.TrimSpace(format))
+		if format != "text" && format != "json" {
+			return fmt.Errorf("unknown output format: %s", format)
+		}
+
+		oldContainer, err := loadGeoSiteContainer(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to load old geosite file: %w", err)
+		}
+
+		var newContainer lib.Container
+		if len(args) > 1 {
+			newContainer, err = loadGeoSiteContainer(args[1])
+		} else {
+			configFile, _ := cmd.Flags().GetString("config")
+			newContainer, err = loadPipelineContainer(configFile)
+		}
+		if newContainer == nil {
+			return fmt.Errorf("failed to load new domain lists: %w", err)
+		}
+
+		diff := lib.DiffContainers(oldContainer, newContainer)
+
+		if format == "json" {
+			data, marshalErr := json.MarshalIndent(diff, "", "  ")
+			if marshalErr != nil {
+				return fmt.Errorf("failed to marshal difference: %w", marshalErr)
+			}
+			fmt.Println(string(data))
+		} else {
+			printDiff(diff)
+		}
+
+		if exitCode, _ := cmd.Flags().GetBool("exit-code"); err == nil && exitCode && !diff.IsEmpty() {
+			return errDifferent
+		}
+		return err
+	},
+}
+
+// loadPipelineContainer processes the inputs and the transforms of the config file, and converts the result
+// with the first v2rayGeoSite output if any, so that its wanted lists and excluded attributes apply.
+// As with loadInstance, a container may be returned along with an error in keep-going mode.
+func loadPipelineContainer(configFile string) (lib.Container, error) {
+	instance, inputErr := loadInstance(configFile)
+	if instance == nil {
+		return nil, inputErr
+	}
+
+	for _, outputConfig := range instance.Config.Output {
+		if !strings.EqualFold(outputConfig.Type, v2ray.TypeGeositeOut) {
+			continue
+		}
+
+		converter, err := outputConfig.GetOutputConverter()
+		if err != nil {
+			return nil, &lib.StageError{Stage: lib.StageConfig, Err: fmt.Errorf("failed to get output converter: %w", err)}
+		}
+		geositeOut, ok := converter.(*v2ray.GeositeOut)
+		if !ok {
+			break
+		}
+
+		container, err := v2ray.NewContainerFromGeoSiteList(geositeOut.ToGeoSiteList(instance.Container))
+		if err != nil {
+			return nil, err
+		}
+		return container, inputErr
+	}
+
+	return instance.Container, inputErr
+}
+
+func printDiff(diff *lib.ContainerDiff) {
+	for _, entryDiff := range diff.AddedEntries {
+		fmt.Println("+", entryDiff.Name, "(", len(entryDiff.AddedRules), "rules)")
+	}
+	for _, entryDiff := range diff.RemovedEntries {
+		fmt.Println("-", entryDiff.Name, "(", len(entryDiff.RemovedRules), "rules)")
+	}
+	for _, entryDiff := range diff.ChangedEntries {
+		fmt.Println("~", entryDiff.Name)
+		for _, rule := range entryDiff.AddedRules {
+			fmt.Println("    +", rule)
+		}
+		for _, rule := range entryDiff.RemovedRules {
+			fmt.Println("    -", rule)
+		}
+		for _, change := range entryDiff.ChangedRules {
+			fmt.Println("    ~", change.Old, "->", change.New)
+		}
+	}
+
+	fmt.Println("---")
+	fmt.Printf("lists: %d added, %d removed, %d changed\n", diff.Summary.AddedEntries, diff.Summary.RemovedEntries, diff.Summary.ChangedEntries)
+	fmt.Printf("rules: %d added, %d removed, %d changed\n", diff.Summary.AddedRules, diff.Summary.RemovedRules, diff.Summary.ChangedRules)
+}
@@ -3,8 +3,10 @@ module github.com/alexxyjiang/domain-list-custom
 go 1.23
 
 require (
+	github.com/sagernet/sing-box v1.11.15
 	github.com/spf13/cobra v1.8.1
 	github.com/v2fly/v2ray-core/v5 v5.16.1
+	golang.org/x/net v0.34.0
 	google.golang.org/protobuf v1.34.2
 )
 
@@ -12,6 +14,14 @@ require (
 	github.com/adrg/xdg v0.4.0 // indirect
 	github.com/golang/protobuf v1.5.4 // indirect
 	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	github.com/miekg/dns v1.1.63 // indirect
+	github.com/sagernet/sing v0.6.10 // indirect
+	github.com/sagernet/sing-dns v0.4.6 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
-	golang.org/x/sys v0.19.0 // indirect
+	go4.org/netipx v0.0.0-20231129151722-fdeea329fbba // indirect
+	golang.org/x/mod v0.20.0 // indirect
+	golang.org/x/sync v0.10.0 // indirect
+	golang.org/x/sys v0.30.0 // indirect
+	golang.org/x/text v0.21.0 // indirect
+	golang.org/x/tools v0.24.0 // indirect
 )
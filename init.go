@@ -1,6 +1,10 @@
 package main
 
 import (
+	_ "github.com/alexxyjiang/domain-list-custom/plugin/clash"
+	_ "github.com/alexxyjiang/domain-list-custom/plugin/dns"
 	_ "github.com/alexxyjiang/domain-list-custom/plugin/plaintext"
+	_ "github.com/alexxyjiang/domain-list-custom/plugin/singbox"
+	_ "github.com/alexxyjiang/domain-list-custom/plugin/transform"
 	_ "github.com/alexxyjiang/domain-list-custom/plugin/v2ray"
 )
@@ -0,0 +1,230 @@
+package lib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
+)
+
+// attributeOperators are the comparison operators of attribute conditions,
+// two-character operators first so that they take precedence
+var attributeOperators = []string{"<=", ">=", "!=", "<", ">", "="}
+
+// IsAttributeKey reports whether key is a valid attribute key: lower case letters, digits, '-', '_' and '.',
+// optionally prefixed with '!' as in !cn. Valid keys can be written in the text format and in
+// attribute expressions, and read back as is.
+func IsAttributeKey(key string) bool {
+	key = strings.TrimPrefix(key, "!")
+	if key == "" {
+		return false
+	}
+	for idx := 0; idx < len(key); idx++ {
+		if !isAttributeKeyChar(key[idx]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isAttributeKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.'
+}
+
+// FormatAttribute converts an attribute to its text form, @key for boolean attributes
+// and @key=value for integer attributes
+func FormatAttribute(attr *router.Domain_Attribute) string {
+	if value, ok := attr.GetTypedValue().(*router.Domain_Attribute_IntValue); ok {
+		return "@" + attr.GetKey() + "=" + strconv.FormatInt(value.IntValue, 10)
+	}
+	return "@" + attr.GetKey()
+}
+
+// ParseAttributeValue creates an attribute from a key and an optional value. An empty value
+// creates a boolean attribute, otherwise the value must be an integer.
+func ParseAttributeValue(key, value string) (*router.Domain_Attribute, error) {
+	key = strings.ToLower(strings.TrimSpace(key))
+	if key == "" {
+		return nil, fmt.Errorf("empty attribute")
+	}
+	if !IsAttributeKey(key) {
+		return nil, fmt.Errorf("invalid attribute %q, keys may only contain letters, digits, '-', '_' and '.'", key)
+	}
+
+	attr := &router.Domain_Attribute{Key: key}
+	if value == "" {
+		attr.TypedValue = &router.Domain_Attribute_BoolValue{BoolValue: true}
+		return attr, nil
+	}
+
+	intValue, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q of attribute %s, must be an integer", value, key)
+	}
+	attr.TypedValue = &router.Domain_Attribute_IntValue{IntValue: intValue}
+	return attr, nil
+}
+
+// AttributeCondition matches domains having an attribute, optionally comparing
+// the integer value of the attribute, e.g. ads, priority<5 or weight=3
+type AttributeCondition struct {
+	Key      string
+	Operator string
+	Value    int64
+}
+
+// ParseAttributeCondition parses an attribute condition in the form of key[operator value]
+func ParseAttributeCondition(s string) (*AttributeCondition, error) {
+	s = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), "@"))
+
+	// The key may start with '!', e.g. !cn, so operators are only searched after it
+	for idx := 1; idx < len(s); idx++ {
+		for _, op := range attributeOperators {
+			if !strings.HasPrefix(s[idx:], op) {
+				continue
+			}
+
+			key := strings.ToLower(strings.TrimSpace(s[:idx]))
+			if !IsAttributeKey(key) {
+				return nil, fmt.Errorf("invalid attribute %q in attribute condition %s", key, s)
+			}
+			valueStr := strings.TrimSpace(s[idx+len(op):])
+			value, err := strconv.ParseInt(valueStr, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q in attribute condition %s, must be an integer", valueStr, s)
+			}
+			return &AttributeCondition{Key: key, Operator: op, Value: value}, nil
+		}
+	}
+
+	if s == "" {
+		return nil, fmt.Errorf("empty attribute condition")
+	}
+	key := strings.ToLower(s)
+	if !IsAttributeKey(key) {
+		return nil, fmt.Errorf("invalid attribute %q in attribute condition", s)
+	}
+	return &AttributeCondition{Key: key}, nil
+}
+
+// Match checks if the domain has an attribute satisfying the condition
+func (c *AttributeCondition) Match(domain *router.Domain) bool {
+	for _, attr := range domain.GetAttribute() {
+		if attr.GetKey() != c.Key {
+			continue
+		}
+		if c.Operator == "" {
+			return true
+		}
+
+		value, ok := attr.GetTypedValue().(*router.Domain_Attribute_IntValue)
+		if !ok {
+			return false
+		}
+
+		switch c.Operator {
+		case "=":
+			return value.IntValue == c.Value
+		case "!=":
+			return value.IntValue != c.Value
+		case "<":
+			return value.IntValue < c.Value
+		case "<=":
+			return value.IntValue <= c.Value
+		case ">":
+			return value.IntValue > c.Value
+		case ">=":
+			return value.IntValue >= c.Value
+		}
+	}
+	return false
+}
+
+func (c *AttributeCondition) String() string {
+	if c.Operator == "" {
+		return "@" + c.Key
+	}
+	return "@" + c.Key + c.Operator + strconv.FormatInt(c.Value, 10)
+}
+
+// ExcludeAttrs maps entry names to the attribute expression of the domains to exclude from them
+type ExcludeAttrs map[string]AttributeExpr
+
+// ParseExcludeAttrs parses comma separated exclude attributes. Each item is either in the form of
+// list@attr1@attr2, excluding the domains with any of the attributes, or in the form of list: EXPR,
+// excluding the domains matching the attribute expression, e.g. cn: @ads || (@!cn && !@cn).
+func ParseExcludeAttrs(s string) (ExcludeAttrs, error) {
+	excludeAttrs := make(ExcludeAttrs)
+	if strings.TrimSpace(s) == "" {
+		return excludeAttrs, nil
+	}
+
+	for _, item := range strings.Split(s, ",") {
+		var filename string
+		var expr AttributeExpr
+
+		if name, exprStr, isExpr := strings.Cut(item, ":"); isExpr {
+			filename = strings.ToUpper(strings.TrimSpace(name))
+			if filename == "" {
+				return nil, fmt.Errorf("empty list name in %q", strings.TrimSpace(item))
+			}
+
+			var err error
+			expr, err = ParseAttributeExpr(exprStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid attribute expression of list %s: %w", filename, err)
+			}
+		} else {
+			exFilenameAttrMap := strings.Split(strings.TrimSpace(item), "@")
+			filename = strings.ToUpper(strings.TrimSpace(exFilenameAttrMap[0]))
+
+			conditions := make([]*AttributeCondition, 0, len(exFilenameAttrMap)-1)
+			for _, attr := range exFilenameAttrMap[1:] {
+				attr = strings.TrimSpace(attr)
+				if len(attr) > 0 {
+					condition, err := ParseAttributeCondition(attr)
+					if err != nil {
+						return nil, err
+					}
+					conditions = append(conditions, condition)
+				}
+			}
+			if expr = AnyAttribute(conditions...); expr == nil {
+				continue
+			}
+		}
+
+		// Items of the same list exclude the domains matching any of them
+		if existing, found := excludeAttrs[filename]; found {
+			expr = &attributeOr{X: existing, Y: expr}
+		}
+		excludeAttrs[filename] = expr
+	}
+
+	return excludeAttrs, nil
+}
+
+// Filter returns the domains of an entry, leaving out the domains matching the attribute expression
+// of the entry. The expression of a list also applies to its NAME@ATTR lists, see Entry.AttributeEntries.
+func (e ExcludeAttrs) Filter(entry *Entry) []*router.Domain {
+	expr := e[entry.GetName()]
+	if name, _, found := strings.Cut(entry.GetName(), AttributeEntrySeparator); found && e[name] != nil {
+		if expr == nil {
+			expr = e[name]
+		} else {
+			expr = &attributeOr{X: e[name], Y: expr}
+		}
+	}
+	if expr == nil {
+		return entry.GetDomains()
+	}
+
+	domains := make([]*router.Domain, 0, len(entry.GetDomains()))
+	for _, domain := range entry.GetDomains() {
+		if !expr.Match(domain) {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
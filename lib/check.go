@@ -0,0 +1,29 @@
+package lib
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	CheckModeError CheckMode = "error"
+	CheckModeWarn  CheckMode = "warn"
+	CheckModeSkip  CheckMode = "skip"
+)
+
+// CheckMode is how an input handles rules that fail validation:
+// fail the input, keep the rule with a warning, or drop the rule with a warning
+type CheckMode string
+
+// ParseCheckMode parses a check mode, returning def if s is empty
+func ParseCheckMode(s string, def CheckMode) (CheckMode, error) {
+	mode := CheckMode(strings.ToLower(strings.TrimSpace(s)))
+	switch mode {
+	case "":
+		return def, nil
+	case CheckModeError, CheckModeWarn, CheckModeSkip:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown check mode: %s", s)
+	}
+}
@@ -3,18 +3,24 @@ package lib
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
+	"slices"
 	"strings"
 )
 
 var (
-	inputConfigCreatorCache  = make(map[string]inputConfigCreator)
-	outputConfigCreatorCache = make(map[string]outputConfigCreator)
-	inputConverterCache      = make(map[string]InputConverter)
-	outputConverterCache     = make(map[string]OutputConverter)
+	inputConfigCreatorCache     = make(map[string]inputConfigCreator)
+	transformConfigCreatorCache = make(map[string]transformConfigCreator)
+	outputConfigCreatorCache    = make(map[string]outputConfigCreator)
+	inputConverterCache         = make(map[string]InputConverter)
+	transformConverterCache     = make(map[string]TransformConverter)
+	outputConverterCache        = make(map[string]OutputConverter)
 )
 
 type inputConfigCreator func(Action, json.RawMessage) (InputConverter, error)
 
+type transformConfigCreator func(Action, json.RawMessage) (TransformConverter, error)
+
 type outputConfigCreator func(Action, json.RawMessage) (OutputConverter, error)
 
 func RegisterInputConfigCreator(id string, fn inputConfigCreator) error {
@@ -35,6 +41,24 @@ func createInputConfig(id string, action Action, data json.RawMessage) (InputCon
 	return fn(action, data)
 }
 
+func RegisterTransformConfigCreator(id string, fn transformConfigCreator) error {
+	id = strings.ToLower(id)
+	if _, found := transformConfigCreatorCache[id]; found {
+		return fmt.Errorf("config creator has already been registered")
+	}
+	transformConfigCreatorCache[id] = fn
+	return nil
+}
+
+func createTransformConfig(id string, action Action, data json.RawMessage) (TransformConverter, error) {
+	id = strings.ToLower(id)
+	fn, found := transformConfigCreatorCache[id]
+	if !found {
+		return nil, fmt.Errorf("unknown config type")
+	}
+	return fn(action, data)
+}
+
 func RegisterOutputConfigCreator(id string, fn outputConfigCreator) error {
 	id = strings.ToLower(id)
 	if _, found := outputConfigCreatorCache[id]; found {
@@ -62,6 +86,15 @@ func RegisterInputConverter(id string, converter InputConverter) error {
 	return nil
 }
 
+func RegisterTransformConverter(id string, converter TransformConverter) error {
+	id = strings.ToLower(id)
+	if _, found := transformConverterCache[id]; found {
+		return fmt.Errorf("converter has already been registered")
+	}
+	transformConverterCache[id] = converter
+	return nil
+}
+
 func RegisterOutputConverter(id string, converter OutputConverter) error {
 	id = strings.ToLower(id)
 	if _, found := outputConverterCache[id]; found {
@@ -73,11 +106,59 @@ func RegisterOutputConverter(id string, converter OutputConverter) error {
 
 // Config is the configuration for converting domain lists
 type Config struct {
-	Input  []ConfigItem `json:"input"`
-	Output []ConfigItem `json:"output"`
+	Input     []ConfigItem `json:"input"`
+	Transform []ConfigItem `json:"transform"`
+	Output    []ConfigItem `json:"output"`
 }
 
-// ConfigItem is a single input or output configuration
+// UnmarshalJSON unmarshals a Config from JSON, checking the actions of each stage
+func (c *Config) UnmarshalJSON(data []byte) error {
+	var tmp struct {
+		Input     []ConfigItem `json:"input"`
+		Transform []ConfigItem `json:"transform"`
+		Output    []ConfigItem `json:"output"`
+		// Deprecated: the dedup section is translated into a dedup transform of all lists,
+		// processed before the other transforms
+		Dedup json.RawMessage `json:"dedup"`
+	}
+
+	if err := json.Unmarshal(data, &tmp); err != nil {
+		return err
+	}
+
+	if len(tmp.Dedup) > 0 && string(tmp.Dedup) != "null" {
+		slog.Warn("dedup section is deprecated, use the dedup transform instead")
+		tmp.Transform = slices.Insert(tmp.Transform, 0, ConfigItem{
+			Type:   "dedup",
+			Action: string(ActionTransform),
+			Args:   tmp.Dedup,
+		})
+	}
+
+	for _, stage := range []struct {
+		name    string
+		items   []ConfigItem
+		actions []Action
+	}{
+		{"input", tmp.Input, InputActions},
+		{"transform", tmp.Transform, TransformActions},
+		{"output", tmp.Output, OutputActions},
+	} {
+		for _, item := range stage.items {
+			if !slices.Contains(stage.actions, Action(strings.ToLower(item.Action))) {
+				return fmt.Errorf("unsupported action of %s [type: %s]: %s", stage.name, item.Type, item.Action)
+			}
+		}
+	}
+
+	c.Input = tmp.Input
+	c.Transform = tmp.Transform
+	c.Output = tmp.Output
+
+	return nil
+}
+
+// ConfigItem is a single input, transform or output configuration
 type ConfigItem struct {
 	Type   string          `json:"type"`
 	Action string          `json:"action"`
@@ -124,6 +205,12 @@ func (c *ConfigItem) GetInputConverter() (InputConverter, error) {
 	return createInputConfig(c.Type, action, c.Args)
 }
 
+// GetTransformConverter returns a TransformConverter for the ConfigItem
+func (c *ConfigItem) GetTransformConverter() (TransformConverter, error) {
+	action := Action(strings.ToLower(c.Action))
+	return createTransformConfig(c.Type, action, c.Args)
+}
+
 // GetOutputConverter returns an OutputConverter for the ConfigItem
 func (c *ConfigItem) GetOutputConverter() (OutputConverter, error) {
 	action := Action(strings.ToLower(c.Action))
@@ -0,0 +1,55 @@
+package lib
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConfigUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name          string
+		data          string
+		wantTransform []string
+		wantErr       bool
+	}{
+		{
+			name:          "dedup section translated into the first transform",
+			data:          `{"dedup": {"minimize": true}, "transform": [{"type": "filter", "action": "transform"}]}`,
+			wantTransform: []string{"dedup", "filter"},
+		},
+		{
+			name:          "null dedup section ignored",
+			data:          `{"dedup": null, "transform": [{"type": "filter", "action": "transform"}]}`,
+			wantTransform: []string{"filter"},
+		},
+		{name: "input action", data: `{"input": [{"type": "text", "action": "Remove"}]}`},
+		{name: "output action in input", data: `{"input": [{"type": "text", "action": "output"}]}`, wantErr: true},
+		{name: "add action in transform", data: `{"transform": [{"type": "dedup", "action": "add"}]}`, wantErr: true},
+		{name: "transform action in output", data: `{"output": [{"type": "text", "action": "transform"}]}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config Config
+			err := json.Unmarshal([]byte(tt.data), &config)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Unmarshal(%s) = %+v, want error", tt.data, config)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal(%s) error = %v", tt.data, err)
+			}
+
+			if len(config.Transform) != len(tt.wantTransform) {
+				t.Fatalf("Unmarshal(%s) transforms = %+v, want types %v", tt.data, config.Transform, tt.wantTransform)
+			}
+			for idx, typ := range tt.wantTransform {
+				if config.Transform[idx].Type != typ || config.Transform[idx].Action != string(ActionTransform) {
+					t.Errorf("Unmarshal(%s) transform %d = %+v, want type %s", tt.data, idx, config.Transform[idx], typ)
+				}
+			}
+		})
+	}
+}
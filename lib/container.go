@@ -4,13 +4,20 @@ import (
 	"fmt"
 	"iter"
 	"log/slog"
+	"maps"
+	"slices"
 	"strings"
 	"sync"
+
+	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
 )
 
 // Container is a container for domain list entries
 type Container interface {
 	Add(entry *Entry) error
+	Remove(name string) error
+	Replace(entry *Entry) error
+	RemoveDomains(name string, predicate func(*router.Domain) bool) (int, error)
 	Get(name string) (*Entry, bool)
 	GetEntry(name string) (*Entry, bool)
 	Has(name string) bool
@@ -58,6 +65,66 @@ func (c *SimpleContainer) Add(entry *Entry) error {
 	return nil
 }
 
+// Remove removes an entry by name
+func (c *SimpleContainer) Remove(name string) error {
+	name = strings.ToUpper(strings.TrimSpace(name))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, found := c.entries[name]; !found {
+		return fmt.Errorf("entry %s not found", name)
+	}
+
+	delete(c.entries, name)
+	slog.Debug("removing entry", "name", name)
+
+	return nil
+}
+
+// Replace adds an entry to the container, replacing the existing entry with the same name
+func (c *SimpleContainer) Replace(entry *Entry) error {
+	if entry == nil {
+		return fmt.Errorf("entry is nil")
+	}
+
+	name := strings.ToUpper(strings.TrimSpace(entry.GetName()))
+	if name == "" {
+		return fmt.Errorf("entry name is empty")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[name] = entry
+	slog.Debug("replacing entry", "name", name, "domains count", len(entry.GetDomains()))
+
+	return nil
+}
+
+// RemoveDomains removes the domains of an entry for which predicate returns true,
+// and returns the number of domains removed
+func (c *SimpleContainer) RemoveDomains(name string, predicate func(*router.Domain) bool) (int, error) {
+	if predicate == nil {
+		return 0, fmt.Errorf("predicate is nil")
+	}
+
+	name = strings.ToUpper(strings.TrimSpace(name))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, found := c.entries[name]
+	if !found {
+		return 0, fmt.Errorf("entry %s not found", name)
+	}
+
+	removed := entry.RemoveDomainsFunc(predicate)
+	slog.Debug("removing domains from entry", "name", name, "domains count", removed)
+
+	return removed, nil
+}
+
 // Get retrieves an entry by name
 func (c *SimpleContainer) Get(name string) (*Entry, bool) {
 	return c.GetEntry(name)
@@ -85,14 +152,14 @@ func (c *SimpleContainer) Has(name string) bool {
 	return found
 }
 
-// Loop iterates over all entries
+// Loop iterates over all entries in the order of their names
 func (c *SimpleContainer) Loop() iter.Seq[*Entry] {
 	return func(yield func(*Entry) bool) {
 		c.mu.RLock()
 		defer c.mu.RUnlock()
 
-		for _, entry := range c.entries {
-			if !yield(entry) {
+		for _, name := range slices.Sorted(maps.Keys(c.entries)) {
+			if !yield(c.entries[name]) {
 				return
 			}
 		}
@@ -107,14 +174,75 @@ func (c *SimpleContainer) Len() int {
 	return len(c.entries)
 }
 
-// GetNames returns all entry names
+// GetNames returns all entry names in sorted order
 func (c *SimpleContainer) GetNames() []string {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	names := make([]string, 0, len(c.entries))
-	for name := range c.entries {
-		names = append(names, name)
+	return slices.Sorted(maps.Keys(c.entries))
+}
+
+// RemoveEntry removes the domains of entry, or the whole entry if rCase is CaseRemoveEntry,
+// from the container. Entries that do not exist in the container are ignored.
+func RemoveEntry(container Container, entry *Entry, rCase CaseRemove) error {
+	if entry == nil {
+		return fmt.Errorf("entry is nil")
+	}
+
+	name := entry.GetName()
+	if !container.Has(name) {
+		slog.Debug("entry to remove not found", "name", name)
+		return nil
 	}
-	return names
+
+	switch rCase {
+	case CaseRemovePrefix:
+		toRemove := make(map[string]bool, len(entry.GetDomains()))
+		for _, domain := range entry.GetDomains() {
+			toRemove[domainKey(domain)] = true
+		}
+		_, err := container.RemoveDomains(name, func(domain *router.Domain) bool {
+			return toRemove[domainKey(domain)]
+		})
+		return err
+	case CaseRemoveEntry:
+		return container.Remove(name)
+	default:
+		return fmt.Errorf("unknown remove case: %d", rCase)
+	}
+}
+
+// FilterAndSortList returns the sorted names of the wanted entries, or of all entries in the
+// container if want is empty, leaving out the excluded entries
+func FilterAndSortList(container Container, want, exclude []string) []string {
+	excludeMap := make(map[string]bool)
+	for _, exclude := range exclude {
+		if exclude = strings.ToUpper(strings.TrimSpace(exclude)); exclude != "" {
+			excludeMap[exclude] = true
+		}
+	}
+
+	wantList := make([]string, 0, len(want))
+	for _, want := range want {
+		if want = strings.ToUpper(strings.TrimSpace(want)); want != "" && !excludeMap[want] {
+			wantList = append(wantList, want)
+		}
+	}
+
+	if len(wantList) > 0 {
+		slices.Sort(wantList)
+		return wantList
+	}
+
+	list := make([]string, 0, 300)
+	for entry := range container.Loop() {
+		name := entry.GetName()
+		if excludeMap[name] {
+			continue
+		}
+		list = append(list, name)
+	}
+
+	slices.Sort(list)
+	return list
 }
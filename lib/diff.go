@@ -0,0 +1,168 @@
+package lib
+
+import (
+	"slices"
+	"strings"
+
+	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
+)
+
+// ContainerDiff is the difference between an old and a new container
+type ContainerDiff struct {
+	Summary        DiffSummary  `json:"summary"`
+	AddedEntries   []*EntryDiff `json:"addedEntries"`
+	RemovedEntries []*EntryDiff `json:"removedEntries"`
+	ChangedEntries []*EntryDiff `json:"changedEntries"`
+}
+
+// DiffSummary counts the changes of a ContainerDiff
+type DiffSummary struct {
+	AddedEntries   int `json:"addedEntries"`
+	RemovedEntries int `json:"removedEntries"`
+	ChangedEntries int `json:"changedEntries"`
+	AddedRules     int `json:"addedRules"`
+	RemovedRules   int `json:"removedRules"`
+	ChangedRules   int `json:"changedRules"`
+}
+
+// EntryDiff is the difference between the rules of an old and a new entry with the same name.
+// Rules with the same type and value but different attributes are reported as changed.
+type EntryDiff struct {
+	Name         string        `json:"name"`
+	AddedRules   []string      `json:"addedRules,omitempty"`
+	RemovedRules []string      `json:"removedRules,omitempty"`
+	ChangedRules []*RuleChange `json:"changedRules,omitempty"`
+}
+
+// RuleChange is a rule whose attributes have changed
+type RuleChange struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// IsEmpty checks if the entries have no difference
+func (d *EntryDiff) IsEmpty() bool {
+	return len(d.AddedRules) == 0 && len(d.RemovedRules) == 0 && len(d.ChangedRules) == 0
+}
+
+// IsEmpty checks if the containers have no difference
+func (d *ContainerDiff) IsEmpty() bool {
+	return len(d.AddedEntries) == 0 && len(d.RemovedEntries) == 0 && len(d.ChangedEntries) == 0
+}
+
+// DiffContainers compares the entries of an old and a new container
+func DiffContainers(oldContainer, newContainer Container) *ContainerDiff {
+	diff := &ContainerDiff{
+		AddedEntries:   make([]*EntryDiff, 0),
+		RemovedEntries: make([]*EntryDiff, 0),
+		ChangedEntries: make([]*EntryDiff, 0),
+	}
+
+	oldNames := oldContainer.GetNames()
+	slices.Sort(oldNames)
+	for _, name := range oldNames {
+		oldEntry, _ := oldContainer.GetEntry(name)
+		newEntry, found := newContainer.GetEntry(name)
+		if !found {
+			newEntry = NewEntry(name)
+		}
+
+		entryDiff := DiffEntries(oldEntry, newEntry)
+		switch {
+		case !found:
+			diff.RemovedEntries = append(diff.RemovedEntries, entryDiff)
+		case !entryDiff.IsEmpty():
+			diff.ChangedEntries = append(diff.ChangedEntries, entryDiff)
+		}
+	}
+
+	newNames := newContainer.GetNames()
+	slices.Sort(newNames)
+	for _, name := range newNames {
+		if oldContainer.Has(name) {
+			continue
+		}
+		newEntry, _ := newContainer.GetEntry(name)
+		diff.AddedEntries = append(diff.AddedEntries, DiffEntries(NewEntry(name), newEntry))
+	}
+
+	diff.Summary.AddedEntries = len(diff.AddedEntries)
+	diff.Summary.RemovedEntries = len(diff.RemovedEntries)
+	diff.Summary.ChangedEntries = len(diff.ChangedEntries)
+	for _, entryDiffs := range [][]*EntryDiff{diff.AddedEntries, diff.RemovedEntries, diff.ChangedEntries} {
+		for _, entryDiff := range entryDiffs {
+			diff.Summary.AddedRules += len(entryDiff.AddedRules)
+			diff.Summary.RemovedRules += len(entryDiff.RemovedRules)
+			diff.Summary.ChangedRules += len(entryDiff.ChangedRules)
+		}
+	}
+
+	return diff
+}
+
+// DiffEntries compares the rules of an old and a new entry
+func DiffEntries(oldEntry, newEntry *Entry) *EntryDiff {
+	entryDiff := &EntryDiff{Name: newEntry.GetName()}
+
+	oldRules := groupRules(oldEntry.GetDomains())
+	newRules := groupRules(newEntry.GetDomains())
+
+	for _, key := range sortedKeys(oldRules) {
+		newForms, found := newRules[key]
+		switch {
+		case !found:
+			entryDiff.RemovedRules = append(entryDiff.RemovedRules, oldRules[key]...)
+		case !slices.Equal(oldRules[key], newForms):
+			entryDiff.ChangedRules = append(entryDiff.ChangedRules, &RuleChange{
+				Old: strings.Join(oldRules[key], " "),
+				New: strings.Join(newForms, " "),
+			})
+		}
+	}
+
+	for _, key := range sortedKeys(newRules) {
+		if _, found := oldRules[key]; !found {
+			entryDiff.AddedRules = append(entryDiff.AddedRules, newRules[key]...)
+		}
+	}
+
+	return entryDiff
+}
+
+// groupRules groups the text forms of domain rules by type and value
+func groupRules(domains []*router.Domain) map[string][]string {
+	rules := make(map[string][]string, len(domains))
+	for _, domain := range domains {
+		key := domainKey(domain)
+		rules[key] = append(rules[key], canonicalRule(domain))
+	}
+	for key, forms := range rules {
+		slices.Sort(forms)
+		rules[key] = slices.Compact(forms)
+	}
+	return rules
+}
+
+// canonicalRule converts a domain rule to text format with sorted attributes
+func canonicalRule(domain *router.Domain) string {
+	rule := FormatDomain(&router.Domain{Type: domain.GetType(), Value: domain.GetValue()})
+	if len(domain.GetAttribute()) == 0 {
+		return rule
+	}
+
+	attrs := make([]string, 0, len(domain.GetAttribute()))
+	for _, attr := range domain.GetAttribute() {
+		attrs = append(attrs, FormatAttribute(attr))
+	}
+	slices.Sort(attrs)
+	return rule + ":" + strings.Join(slices.Compact(attrs), ",")
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,97 @@
+package lib
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+const (
+	maxDomainLength = 253
+	maxLabelLength  = 63
+)
+
+// idnaProfile maps and converts internationalized domain names to punycode,
+// leaving the validation of labels to NormalizeDomain
+var idnaProfile = idna.New(
+	idna.MapForLookup(),
+	idna.BidiRule(),
+	idna.StrictDomainName(false),
+)
+
+// DomainError is a diagnostic for a domain failing validation
+type DomainError struct {
+	Domain string
+	Label  string
+	Reason string
+}
+
+func (e *DomainError) Error() string {
+	if e.Label != "" {
+		return fmt.Sprintf("invalid domain %q: label %q %s", e.Domain, e.Label, e.Reason)
+	}
+	return fmt.Sprintf("invalid domain %q: %s", e.Domain, e.Reason)
+}
+
+// NormalizeDomain converts a domain to its lower-cased ASCII form, converting internationalized
+// domain names to punycode and stripping the trailing dot. It returns a *DomainError if the domain
+// or any of its labels has an invalid length or charset.
+func NormalizeDomain(domain string) (string, error) {
+	original := domain
+	domain = strings.TrimSuffix(domain, ".")
+	if domain == "" {
+		return "", &DomainError{Domain: original, Reason: "is empty"}
+	}
+
+	ascii := strings.ToLower(domain)
+	if !isASCII(ascii) {
+		var err error
+		if ascii, err = idnaProfile.ToASCII(domain); err != nil {
+			return "", &DomainError{Domain: original, Reason: err.Error()}
+		}
+	}
+
+	if len(ascii) > maxDomainLength {
+		return "", &DomainError{Domain: original, Reason: fmt.Sprintf("is longer than %d characters", maxDomainLength)}
+	}
+
+	for _, label := range strings.Split(ascii, ".") {
+		if label == "" {
+			return "", &DomainError{Domain: original, Reason: "contains an empty label"}
+		}
+		if reason := validateLabel(label); reason != "" {
+			return "", &DomainError{Domain: original, Label: label, Reason: reason}
+		}
+	}
+
+	return ascii, nil
+}
+
+// validateLabel returns the reason why a non-empty domain label is invalid, or an empty string
+// if it is valid. Underscores are accepted as they are common in service names like _dmarc.
+func validateLabel(label string) string {
+	switch {
+	case len(label) > maxLabelLength:
+		return fmt.Sprintf("is longer than %d characters", maxLabelLength)
+	case label[0] == '-' || label[len(label)-1] == '-':
+		return "starts or ends with a hyphen"
+	}
+
+	for _, c := range label {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return fmt.Sprintf("contains invalid character %q", c)
+		}
+	}
+	return ""
+}
+
+func isASCII(s string) bool {
+	for idx := 0; idx < len(s); idx++ {
+		if s[idx] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,135 @@
+package lib
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeDomain(t *testing.T) {
+	longLabel := strings.Repeat("a", maxLabelLength)
+	longDomain := strings.Repeat(longLabel+".", 4)[:maxDomainLength+1]
+
+	tests := []struct {
+		name    string
+		domain  string
+		want    string
+		wantErr *DomainError
+	}{
+		{name: "ascii", domain: "example.com", want: "example.com"},
+		{name: "upper case", domain: "WWW.Example.COM", want: "www.example.com"},
+		{name: "trailing dot", domain: "example.com.", want: "example.com"},
+		{name: "underscore", domain: "_dmarc.example.com", want: "_dmarc.example.com"},
+		{name: "idna", domain: "例子.测试", want: "xn--fsqu00a.xn--0zwm56d"},
+		{name: "idna upper case", domain: "Bücher.de", want: "xn--bcher-kva.de"},
+		{name: "idna trailing dot", domain: "bücher.de.", want: "xn--bcher-kva.de"},
+		{name: "idna mapping", domain: "ｅｘａｍｐｌｅ。com", want: "example.com"},
+		{name: "punycode", domain: "xn--fsqu00a.xn--0zwm56d", want: "xn--fsqu00a.xn--0zwm56d"},
+		{name: "punycode upper case", domain: "XN--FIQS8S.cn", want: "xn--fiqs8s.cn"},
+		{name: "longest label", domain: longLabel + ".com", want: longLabel + ".com"},
+		{
+			name:    "empty",
+			domain:  "",
+			wantErr: &DomainError{Domain: "", Reason: "is empty"},
+		},
+		{
+			name:    "only a dot",
+			domain:  ".",
+			wantErr: &DomainError{Domain: ".", Reason: "is empty"},
+		},
+		{
+			name:    "empty label",
+			domain:  "a..com",
+			wantErr: &DomainError{Domain: "a..com", Reason: "contains an empty label"},
+		},
+		{
+			name:    "leading dot",
+			domain:  ".a.com",
+			wantErr: &DomainError{Domain: ".a.com", Reason: "contains an empty label"},
+		},
+		{
+			name:    "over-long label",
+			domain:  longLabel + "a.com",
+			wantErr: &DomainError{Domain: longLabel + "a.com", Label: longLabel + "a", Reason: "is longer than 63 characters"},
+		},
+		{
+			name:    "over-long domain",
+			domain:  longDomain,
+			wantErr: &DomainError{Domain: longDomain, Reason: "is longer than 253 characters"},
+		},
+		{
+			name:    "leading hyphen",
+			domain:  "-a.com",
+			wantErr: &DomainError{Domain: "-a.com", Label: "-a", Reason: "starts or ends with a hyphen"},
+		},
+		{
+			name:    "trailing hyphen",
+			domain:  "a.com-",
+			wantErr: &DomainError{Domain: "a.com-", Label: "com-", Reason: "starts or ends with a hyphen"},
+		},
+		{
+			name:    "invalid character",
+			domain:  "a b.com",
+			wantErr: &DomainError{Domain: "a b.com", Label: "a b", Reason: `contains invalid character ' '`},
+		},
+		{
+			name:    "invalid character after idna",
+			domain:  "bü*cher.de",
+			wantErr: &DomainError{Domain: "bü*cher.de", Label: "xn--b*cher-3ya", Reason: `contains invalid character '*'`},
+		},
+		{
+			name:    "invalid idna",
+			domain:  "a\u200d.com",
+			wantErr: &DomainError{Domain: "a\u200d.com", Reason: `idna: invalid label "a\u200d"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeDomain(tt.domain)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("NormalizeDomain(%q) error = %v", tt.domain, err)
+				}
+				if got != tt.want {
+					t.Errorf("NormalizeDomain(%q) = %q, want %q", tt.domain, got, tt.want)
+				}
+				return
+			}
+
+			var domainErr *DomainError
+			if !errors.As(err, &domainErr) {
+				t.Fatalf("NormalizeDomain(%q) = %q, %v, want a *DomainError", tt.domain, got, err)
+			}
+			if *domainErr != *tt.wantErr {
+				t.Errorf("NormalizeDomain(%q) error = %#v, want %#v", tt.domain, *domainErr, *tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDomainError(t *testing.T) {
+	tests := []struct {
+		err  *DomainError
+		want string
+	}{
+		{
+			err:  &DomainError{Domain: "", Reason: "is empty"},
+			want: `invalid domain "": is empty`,
+		},
+		{
+			err:  &DomainError{Domain: "a..com", Reason: "contains an empty label"},
+			want: `invalid domain "a..com": contains an empty label`,
+		},
+		{
+			err:  &DomainError{Domain: "-a.com", Label: "-a", Reason: "starts or ends with a hyphen"},
+			want: `invalid domain "-a.com": label "-a" starts or ends with a hyphen`,
+		},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("Error() = %q, want %q", got, tt.want)
+		}
+	}
+}
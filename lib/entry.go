@@ -1,11 +1,16 @@
 package lib
 
 import (
+	"slices"
 	"strings"
 
 	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
 )
 
+// AttributeEntrySeparator separates the name of an entry from an attribute key in the names of
+// attribute entries, e.g. GOOGLE@CN
+const AttributeEntrySeparator = "@"
+
 // Entry is a single domain list entry
 type Entry struct {
 	Name    string
@@ -40,42 +45,205 @@ func (e *Entry) AddDomains(domains []*router.Domain) {
 	}
 }
 
+// RemoveDomainsFunc removes the domains for which predicate returns true,
+// and returns the number of domains removed
+func (e *Entry) RemoveDomainsFunc(predicate func(*router.Domain) bool) int {
+	before := len(e.Domains)
+	e.Domains = slices.DeleteFunc(e.Domains, predicate)
+	return before - len(e.Domains)
+}
+
+// Deduplicate removes the domains with the same type, value and attributes as a preceding domain.
+// The values of full and domain rules are compared case-insensitively. If minimize is true, it
+// also removes the full and domain rules already covered by a domain rule with the same
+// attributes, e.g. full:a.b.com and domain:x.b.com when domain:b.com is present. keyword and
+// regexp rules are only removed when duplicated. It returns the number of domains removed.
+func (e *Entry) Deduplicate(minimize bool) int {
+	// Collect root domains by attributes for coverage checks
+	rootDomains := make(map[string]map[string]bool)
+	if minimize {
+		for _, domain := range e.Domains {
+			if domain.GetType() != router.Domain_RootDomain {
+				continue
+			}
+			attrKey := attributesKey(domain)
+			if rootDomains[attrKey] == nil {
+				rootDomains[attrKey] = make(map[string]bool)
+			}
+			rootDomains[attrKey][dedupValue(domain)] = true
+		}
+	}
+
+	seen := make(map[string]bool, len(e.Domains))
+	return e.RemoveDomainsFunc(func(domain *router.Domain) bool {
+		value := dedupValue(domain)
+		key := domain.GetType().String() + ":" + value + attributesKey(domain)
+		if seen[key] {
+			return true
+		}
+		seen[key] = true
+
+		if !minimize {
+			return false
+		}
+
+		switch domain.GetType() {
+		case router.Domain_Full:
+			// Covered by a domain rule of the same or a parent domain
+			return isCovered(rootDomains[attributesKey(domain)], value, true)
+		case router.Domain_RootDomain:
+			// Covered by a domain rule of a parent domain
+			return isCovered(rootDomains[attributesKey(domain)], value, false)
+		}
+		return false
+	})
+}
+
 // GetDomains returns all domains in the entry
 func (e *Entry) GetDomains() []*router.Domain {
 	return e.Domains
 }
 
+// GetAttributes returns the sorted keys of the attributes present in the domains of the entry
+func (e *Entry) GetAttributes() []string {
+	keys := make([]string, 0)
+	for _, domain := range e.Domains {
+		for _, attr := range domain.GetAttribute() {
+			keys = append(keys, attr.GetKey())
+		}
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+// AttributeEntries returns an entry named NAME@ATTR for every attribute present in the entry,
+// with the domains having the attribute, in the order of the attribute keys. V2Ray clients
+// select the same domains with geosite:name@attr. Attribute entries are not expanded again.
+func (e *Entry) AttributeEntries() []*Entry {
+	if strings.Contains(e.GetName(), AttributeEntrySeparator) {
+		return nil
+	}
+
+	keys := e.GetAttributes()
+	entries := make([]*Entry, 0, len(keys))
+	for _, key := range keys {
+		entry := NewEntry(e.GetName() + AttributeEntrySeparator + key)
+		for _, domain := range e.Domains {
+			if slices.ContainsFunc(domain.GetAttribute(), func(attr *router.Domain_Attribute) bool {
+				return attr.GetKey() == key
+			}) {
+				entry.AddDomain(domain)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// NewAttributeEntries returns the attribute entries of the entry of the container with the name,
+// see Entry.AttributeEntries, leaving out the names already in the container, such as the lists
+// generated by the attributeLists transform or loaded by an input
+func NewAttributeEntries(container Container, name string) []*Entry {
+	entry, found := container.GetEntry(name)
+	if !found {
+		return nil
+	}
+
+	return slices.DeleteFunc(entry.AttributeEntries(), func(attrEntry *Entry) bool {
+		return container.Has(attrEntry.GetName())
+	})
+}
+
 // MarshalText converts the entry to text format
 func (e *Entry) MarshalText() ([]byte, error) {
 	result := make([]byte, 0, 1024*512)
 
 	for _, domain := range e.Domains {
-		ruleVal := strings.TrimSpace(domain.GetValue())
-		if len(ruleVal) == 0 {
+		ruleString := FormatDomain(domain)
+		if ruleString == "" {
 			continue
 		}
+		result = append(result, []byte(ruleString+"\n")...)
+	}
 
-		var ruleString string
-		switch domain.Type {
-		case router.Domain_Full:
-			ruleString = "full:" + ruleVal
-		case router.Domain_RootDomain:
-			ruleString = "domain:" + ruleVal
-		case router.Domain_Plain:
-			ruleString = "keyword:" + ruleVal
-		case router.Domain_Regex:
-			ruleString = "regexp:" + ruleVal
-		}
+	return result, nil
+}
 
-		if len(domain.Attribute) > 0 {
-			ruleString += ":"
-			for _, attr := range domain.Attribute {
-				ruleString += "@" + attr.GetKey() + ","
-			}
-			ruleString = strings.TrimRight(ruleString, ",")
+// FormatDomain converts a domain rule to text format, e.g. domain:example.com:@ads,@cn.
+// It returns an empty string if the rule has no value.
+func FormatDomain(domain *router.Domain) string {
+	ruleVal := strings.TrimSpace(domain.GetValue())
+	if len(ruleVal) == 0 {
+		return ""
+	}
+
+	var ruleString string
+	switch domain.Type {
+	case router.Domain_Full:
+		ruleString = "full:" + ruleVal
+	case router.Domain_RootDomain:
+		ruleString = "domain:" + ruleVal
+	case router.Domain_Plain:
+		ruleString = "keyword:" + ruleVal
+	case router.Domain_Regex:
+		ruleString = "regexp:" + ruleVal
+	}
+
+	if len(domain.Attribute) > 0 {
+		ruleString += ":"
+		for _, attr := range domain.Attribute {
+			ruleString += FormatAttribute(attr) + ","
 		}
-		result = append(result, []byte(ruleString+"\n")...)
+		ruleString = strings.TrimRight(ruleString, ",")
 	}
 
-	return result, nil
+	return ruleString
+}
+
+// domainKey returns the key identifying a domain rule by its type and value
+func domainKey(domain *router.Domain) string {
+	return domain.GetType().String() + ":" + domain.GetValue()
+}
+
+// attributesKey returns the key identifying the attribute set of a domain rule
+func attributesKey(domain *router.Domain) string {
+	if len(domain.GetAttribute()) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(domain.GetAttribute()))
+	for _, attr := range domain.GetAttribute() {
+		keys = append(keys, FormatAttribute(attr))
+	}
+	slices.Sort(keys)
+	return strings.Join(slices.Compact(keys), "")
+}
+
+// dedupValue returns the value of domain to compare when deduplicating, lowercased for full and
+// domain rules as domain names are case insensitive
+func dedupValue(domain *router.Domain) string {
+	switch domain.GetType() {
+	case router.Domain_Full, router.Domain_RootDomain:
+		return strings.ToLower(domain.GetValue())
+	}
+	return domain.GetValue()
+}
+
+// isCovered checks if value, or any of its parent domains, is in rootDomains
+func isCovered(rootDomains map[string]bool, value string, includeSelf bool) bool {
+	if len(rootDomains) == 0 {
+		return false
+	}
+
+	if includeSelf && rootDomains[value] {
+		return true
+	}
+
+	for idx := strings.IndexByte(value, '.'); idx != -1; idx = strings.IndexByte(value, '.') {
+		value = value[idx+1:]
+		if rootDomains[value] {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,160 @@
+package lib
+
+import (
+	"reflect"
+	"testing"
+
+	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
+)
+
+func newTestDomain(value string, keys ...string) *router.Domain {
+	domain := &router.Domain{Type: router.Domain_RootDomain, Value: value}
+	for _, key := range keys {
+		domain.Attribute = append(domain.Attribute, &router.Domain_Attribute{
+			Key:        key,
+			TypedValue: &router.Domain_Attribute_BoolValue{BoolValue: true},
+		})
+	}
+	return domain
+}
+
+func entryNames(entries []*Entry) []string {
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.GetName())
+	}
+	return names
+}
+
+func TestNewAttributeEntries(t *testing.T) {
+	container := NewSimpleContainer()
+	google := NewEntry("google")
+	google.AddDomain(newTestDomain("google.com"))
+	google.AddDomain(newTestDomain("google.cn", "cn"))
+	google.AddDomain(newTestDomain("doubleclick.net", "ads", "cn"))
+	container.Add(google)
+
+	if got, want := entryNames(NewAttributeEntries(container, "google")), []string{"GOOGLE@ADS", "GOOGLE@CN"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("NewAttributeEntries() = %v, want %v", got, want)
+	}
+
+	existing := NewEntry("google@cn")
+	existing.AddDomain(newTestDomain("example.com"))
+	container.Add(existing)
+
+	attrEntries := NewAttributeEntries(container, "google")
+	if got, want := entryNames(attrEntries), []string{"GOOGLE@ADS"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("NewAttributeEntries() with existing list = %v, want %v", got, want)
+	}
+	if len(attrEntries[0].GetDomains()) != 1 || attrEntries[0].GetDomains()[0].GetValue() != "doubleclick.net" {
+		t.Errorf("NewAttributeEntries() GOOGLE@ADS domains = %v", attrEntries[0].GetDomains())
+	}
+
+	if got := NewAttributeEntries(container, "google@cn"); len(got) != 0 {
+		t.Errorf("NewAttributeEntries() of an attribute list = %v, want none", entryNames(got))
+	}
+	if got := NewAttributeEntries(container, "missing"); len(got) != 0 {
+		t.Errorf("NewAttributeEntries() of a missing list = %v, want none", entryNames(got))
+	}
+}
+
+func TestExcludeAttrsFilterAttributeEntry(t *testing.T) {
+	excludeAttrs, err := ParseExcludeAttrs("google@ads,google@cn: @cn && !@ads")
+	if err != nil {
+		t.Fatalf("ParseExcludeAttrs() error = %v", err)
+	}
+
+	entry := NewEntry("google@cn")
+	entry.AddDomain(newTestDomain("google.cn", "cn"))
+	entry.AddDomain(newTestDomain("doubleclick.net", "ads", "cn"))
+	entry.AddDomain(newTestDomain("google.com.hk", "cn", "hk"))
+
+	// The exclusions of GOOGLE apply along with those of GOOGLE@CN
+	if got := excludeAttrs.Filter(entry); len(got) != 0 {
+		t.Errorf("Filter() = %v, want none", got)
+	}
+
+	excludeAttrs, err = ParseExcludeAttrs("google@ads")
+	if err != nil {
+		t.Fatalf("ParseExcludeAttrs() error = %v", err)
+	}
+	got := excludeAttrs.Filter(entry)
+	if len(got) != 2 || got[0].GetValue() != "google.cn" || got[1].GetValue() != "google.com.hk" {
+		t.Errorf("Filter() = %v, want google.cn and google.com.hk", got)
+	}
+}
+
+func TestEntryDeduplicate(t *testing.T) {
+	tests := []struct {
+		name        string
+		rules       []string
+		minimize    bool
+		want        []string
+		wantRemoved int
+	}{
+		{
+			name:        "exact duplicates",
+			rules:       []string{"domain:a.com", "full:a.com", "domain:a.com", "keyword:a", "keyword:a", "regexp:^a$", "regexp:^a$"},
+			want:        []string{"domain:a.com", "full:a.com", "keyword:a", "regexp:^a$"},
+			wantRemoved: 3,
+		},
+		{
+			name:        "case duplicates",
+			rules:       []string{"domain:a.com", "domain:A.com", "full:WWW.a.com", "full:www.A.COM"},
+			want:        []string{"domain:a.com", "full:WWW.a.com"},
+			wantRemoved: 2,
+		},
+		{
+			name:        "case of keyword and regexp rules",
+			rules:       []string{"keyword:a", "keyword:A", "regexp:^a$", "regexp:^A$"},
+			minimize:    true,
+			want:        []string{"keyword:A", "keyword:a", "regexp:^A$", "regexp:^a$"},
+			wantRemoved: 0,
+		},
+		{
+			name:        "duplicates with the same attribute set",
+			rules:       []string{"domain:a.com @ads @cn", "domain:a.com @cn @ads", "domain:a.com @cn"},
+			want:        []string{"domain:a.com:@ads,@cn", "domain:a.com:@cn"},
+			wantRemoved: 1,
+		},
+		{
+			name:        "covered rules are kept without minimize",
+			rules:       []string{"domain:a.com", "full:www.a.com", "domain:x.a.com"},
+			want:        []string{"domain:a.com", "domain:x.a.com", "full:www.a.com"},
+			wantRemoved: 0,
+		},
+		{
+			name:        "domain rule covers full and domain subdomains",
+			rules:       []string{"full:a.com", "full:www.a.com", "domain:x.a.com", "domain:y.x.A.com", "domain:a.com", "full:b.com", "domain:aa.com"},
+			minimize:    true,
+			want:        []string{"domain:a.com", "domain:aa.com", "full:b.com"},
+			wantRemoved: 4,
+		},
+		{
+			name:        "rules with other attribute sets are kept",
+			rules:       []string{"domain:a.com @cn", "full:www.a.com", "domain:x.a.com @ads", "full:y.a.com @ads @cn", "full:z.a.com @cn"},
+			minimize:    true,
+			want:        []string{"domain:a.com:@cn", "domain:x.a.com:@ads", "full:www.a.com", "full:y.a.com:@ads,@cn"},
+			wantRemoved: 1,
+		},
+		{
+			name:        "keyword and regexp rules are never covered",
+			rules:       []string{"domain:a.com", "keyword:www.a.com", "regexp:^www\\.a\\.com$", "keyword:a.com"},
+			minimize:    true,
+			want:        []string{"domain:a.com", "keyword:a.com", "keyword:www.a.com", "regexp:^www\\.a\\.com$"},
+			wantRemoved: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := newTestEntry("test", tt.rules...)
+			if got := entry.Deduplicate(tt.minimize); got != tt.wantRemoved {
+				t.Errorf("Deduplicate() = %d, want %d", got, tt.wantRemoved)
+			}
+			if got := entryRules(entry); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Deduplicate() rules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
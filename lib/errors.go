@@ -0,0 +1,64 @@
+package lib
+
+import (
+	"errors"
+)
+
+const (
+	StageConfig    Stage = "config"
+	StageInput     Stage = "input"
+	StageTransform Stage = "transform"
+	StageOutput    Stage = "output"
+)
+
+// Stage is a stage of the conversion process
+type Stage string
+
+// StageError is an error occurred in a stage of the conversion process
+type StageError struct {
+	Stage Stage
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
+
+func newStageError(stage Stage, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &StageError{Stage: stage, Err: err}
+}
+
+// ErrorStage returns the stage of the first StageError in the tree of err, or an empty stage if none
+func ErrorStage(err error) Stage {
+	var stageErr *StageError
+	if errors.As(err, &stageErr) {
+		return stageErr.Stage
+	}
+	return ""
+}
+
+// FlattenErrors returns the errors joined in the tree of err, such as the errors of the plugins which
+// failed in keep-going mode, expanding nested joins so that every error is returned on its own
+func FlattenErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+
+	errs := make([]error, 0)
+	for _, err := range joined.Unwrap() {
+		errs = append(errs, FlattenErrors(err)...)
+	}
+	return errs
+}
@@ -0,0 +1,57 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestFlattenErrors(t *testing.T) {
+	input1 := newStageError(StageInput, fmt.Errorf("input 1 failed"))
+	input2 := newStageError(StageInput, fmt.Errorf("input 2 failed"))
+	input3 := newStageError(StageConfig, fmt.Errorf("input 3 skipped"))
+	output := newStageError(StageOutput, fmt.Errorf("output failed"))
+
+	tests := []struct {
+		name string
+		err  error
+		want []error
+	}{
+		{name: "nil", err: nil, want: nil},
+		{name: "single", err: input1, want: []error{input1}},
+		{name: "joined", err: errors.Join(input1, input2), want: []error{input1, input2}},
+		{
+			// Run joins the joined errors of RunInput, RunTransform and RunOutput
+			name: "nested joins of keep-going mode",
+			err:  errors.Join(errors.Join(input1, input2, input3), nil, errors.Join(output)),
+			want: []error{input1, input2, input3, output},
+		},
+		{
+			name: "wrapped errors are kept whole",
+			err:  errors.Join(fmt.Errorf("failed to load: %w", input1), input2),
+			want: []error{fmt.Errorf("failed to load: %w", input1), input2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FlattenErrors(tt.err)
+			if len(got) != len(tt.want) {
+				t.Fatalf("FlattenErrors() returned %d errors %v, want %d", len(got), got, len(tt.want))
+			}
+			for idx := range got {
+				if got[idx].Error() != tt.want[idx].Error() {
+					t.Errorf("FlattenErrors()[%d] = %q, want %q", idx, got[idx], tt.want[idx])
+				}
+			}
+		})
+	}
+
+	errs := FlattenErrors(errors.Join(errors.Join(input1, input2, input3), errors.Join(output)))
+	stages := []Stage{StageInput, StageInput, StageConfig, StageOutput}
+	for idx, err := range errs {
+		if stage := ErrorStage(err); stage != stages[idx] {
+			t.Errorf("ErrorStage(FlattenErrors()[%d]) = %q, want %q", idx, stage, stages[idx])
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package lib
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
+)
+
+// AttributeExpr is a boolean expression over the attributes of a domain rule, such as
+// @cn && !@ads, !(@ads || @!cn) or @priority<5 || @weight>=3
+type AttributeExpr interface {
+	Match(domain *router.Domain) bool
+	String() string
+}
+
+type attributeNot struct {
+	X AttributeExpr
+}
+
+func (e *attributeNot) Match(domain *router.Domain) bool {
+	return !e.X.Match(domain)
+}
+
+func (e *attributeNot) String() string {
+	return "!" + e.X.String()
+}
+
+type attributeAnd struct {
+	X, Y AttributeExpr
+}
+
+func (e *attributeAnd) Match(domain *router.Domain) bool {
+	return e.X.Match(domain) && e.Y.Match(domain)
+}
+
+func (e *attributeAnd) String() string {
+	return "(" + e.X.String() + " && " + e.Y.String() + ")"
+}
+
+type attributeOr struct {
+	X, Y AttributeExpr
+}
+
+func (e *attributeOr) Match(domain *router.Domain) bool {
+	return e.X.Match(domain) || e.Y.Match(domain)
+}
+
+func (e *attributeOr) String() string {
+	return "(" + e.X.String() + " || " + e.Y.String() + ")"
+}
+
+// AnyAttribute returns an expression matching domains matching any of the conditions
+func AnyAttribute(conditions ...*AttributeCondition) AttributeExpr {
+	if len(conditions) == 0 {
+		return nil
+	}
+
+	var expr AttributeExpr = conditions[0]
+	for _, condition := range conditions[1:] {
+		expr = &attributeOr{X: expr, Y: condition}
+	}
+	return expr
+}
+
+// ExprError is a syntax error in an attribute expression, located by the 1-based column
+// of the offending character
+type ExprError struct {
+	Expr   string
+	Column int
+	Msg    string
+}
+
+func (e *ExprError) Error() string {
+	return fmt.Sprintf("%s at column %d of %q", e.Msg, e.Column, e.Expr)
+}
+
+// ParseAttributeExpr parses an attribute expression. The grammar is:
+//
+//	expr    = and { "||" and }
+//	and     = unary { "&&" unary }
+//	unary   = "!" unary | primary
+//	primary = "(" expr ")" | "@" key [ operator integer ]
+//
+// where key may start with '!', e.g. @!cn, and operator is one of =, !=, <, <=, > and >=.
+func ParseAttributeExpr(s string) (AttributeExpr, error) {
+	p := &exprParser{src: s}
+
+	p.skipSpaces()
+	if p.pos == len(p.src) {
+		return nil, p.errorf("empty expression")
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+	if p.pos < len(p.src) {
+		return nil, p.unexpected()
+	}
+	return expr, nil
+}
+
+type exprParser struct {
+	src string
+	pos int
+}
+
+func (p *exprParser) errorf(format string, args ...any) *ExprError {
+	return &ExprError{
+		Expr:   p.src,
+		Column: utf8.RuneCountInString(p.src[:p.pos]) + 1,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+func (p *exprParser) unexpected() *ExprError {
+	if p.pos >= len(p.src) {
+		return p.errorf("unexpected end of expression")
+	}
+	r, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+	return p.errorf("unexpected %q", r)
+}
+
+func (p *exprParser) skipSpaces() {
+	for p.pos < len(p.src) && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// consume skips spaces and the token if the remaining source starts with it
+func (p *exprParser) consume(token string) bool {
+	p.skipSpaces()
+	if strings.HasPrefix(p.src[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) parseOr() (AttributeExpr, error) {
+	expr, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.consume("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		expr = &attributeOr{X: expr, Y: right}
+	}
+	return expr, nil
+}
+
+func (p *exprParser) parseAnd() (AttributeExpr, error) {
+	expr, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.consume("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		expr = &attributeAnd{X: expr, Y: right}
+	}
+	return expr, nil
+}
+
+func (p *exprParser) parseUnary() (AttributeExpr, error) {
+	if p.consume("!") {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &attributeNot{X: expr}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (AttributeExpr, error) {
+	if p.consume("(") {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")") {
+			return nil, p.errorf("missing ')'")
+		}
+		return expr, nil
+	}
+
+	p.skipSpaces()
+	if p.pos >= len(p.src) || p.src[p.pos] != '@' {
+		return nil, p.unexpected()
+	}
+	p.pos++
+
+	// The key may start with '!', e.g. @!cn
+	start := p.pos
+	if p.pos < len(p.src) && p.src[p.pos] == '!' {
+		p.pos++
+	}
+	for p.pos < len(p.src) && (isAttributeKeyChar(p.src[p.pos]) || p.src[p.pos] >= 'A' && p.src[p.pos] <= 'Z') {
+		p.pos++
+	}
+	key := p.src[start:p.pos]
+	if key == "" || key == "!" {
+		return nil, p.errorf("missing attribute name")
+	}
+	condition := &AttributeCondition{Key: strings.ToLower(key)}
+
+	p.skipSpaces()
+	for _, op := range attributeOperators {
+		if !strings.HasPrefix(p.src[p.pos:], op) {
+			continue
+		}
+		p.pos += len(op)
+		p.skipSpaces()
+
+		valueStart := p.pos
+		if p.pos < len(p.src) && p.src[p.pos] == '-' {
+			p.pos++
+		}
+		for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+			p.pos++
+		}
+		value, err := strconv.ParseInt(p.src[valueStart:p.pos], 10, 64)
+		if err != nil {
+			p.pos = valueStart
+			return nil, p.errorf("expected an integer after %q", op)
+		}
+		condition.Operator, condition.Value = op, value
+		break
+	}
+
+	return condition, nil
+}
@@ -0,0 +1,124 @@
+package lib
+
+import (
+	"errors"
+	"testing"
+
+	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
+)
+
+func TestParseAttributeExpr(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		want string
+	}{
+		{name: "single attribute", expr: "@ads", want: "@ads"},
+		{name: "key is lower cased", expr: "@ADS", want: "@ads"},
+		{name: "negated key", expr: "@!cn", want: "@!cn"},
+		{name: "not", expr: "!@ads", want: "!@ads"},
+		{name: "double not", expr: "!!@ads", want: "!!@ads"},
+		{name: "and binds tighter than or", expr: "@a || @b && @c", want: "(@a || (@b && @c))"},
+		{name: "and binds tighter than or on the left", expr: "@a && @b || @c", want: "((@a && @b) || @c)"},
+		{name: "not binds tighter than and", expr: "!@a && @b", want: "(!@a && @b)"},
+		{name: "not of parentheses", expr: "!(@a || @b)", want: "!(@a || @b)"},
+		{name: "parentheses override precedence", expr: "(@a || @b) && @c", want: "((@a || @b) && @c)"},
+		{name: "left associative", expr: "@a || @b || @c", want: "((@a || @b) || @c)"},
+		{name: "nested parentheses", expr: "((@a))", want: "@a"},
+		{name: "comparison", expr: "@priority<5", want: "@priority<5"},
+		{name: "comparison with spaces", expr: "@priority >= -3", want: "@priority>=-3"},
+		{name: "not equal is not negation", expr: "@priority!=1 && !@ads", want: "(@priority!=1 && !@ads)"},
+		{name: "no spaces", expr: "@a&&!@b||@c", want: "((@a && !@b) || @c)"},
+		{name: "tabs and spaces", expr: "\t@a  &&\t@b ", want: "(@a && @b)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAttributeExpr(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseAttributeExpr(%q) error = %v", tt.expr, err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("ParseAttributeExpr(%q) = %s, want %s", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAttributeExprError(t *testing.T) {
+	tests := []struct {
+		name   string
+		expr   string
+		column int
+	}{
+		{name: "empty", expr: "", column: 1},
+		{name: "only spaces", expr: "   ", column: 4},
+		{name: "missing at sign", expr: "ads", column: 1},
+		{name: "missing attribute name", expr: "@ && @b", column: 2},
+		{name: "negated empty name", expr: "@!", column: 3},
+		{name: "missing operand of and", expr: "@a &&", column: 6},
+		{name: "missing operand of or", expr: "@a || || @b", column: 7},
+		{name: "missing operand of not", expr: "!", column: 2},
+		{name: "unclosed parenthesis", expr: "(@a || @b", column: 10},
+		{name: "unopened parenthesis", expr: "@a || @b)", column: 9},
+		{name: "empty parentheses", expr: "()", column: 2},
+		{name: "single ampersand", expr: "@a & @b", column: 4},
+		{name: "missing operator", expr: "@a @b", column: 4},
+		{name: "non integer value", expr: "@priority<high", column: 11},
+		{name: "missing value", expr: "@priority>=", column: 12},
+		{name: "columns count runes", expr: "@a || 例子", column: 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAttributeExpr(tt.expr)
+			if err == nil {
+				t.Fatalf("ParseAttributeExpr(%q) = %s, want error", tt.expr, got)
+			}
+			var exprErr *ExprError
+			if !errors.As(err, &exprErr) {
+				t.Fatalf("ParseAttributeExpr(%q) error = %v, want *ExprError", tt.expr, err)
+			}
+			if exprErr.Column != tt.column {
+				t.Errorf("ParseAttributeExpr(%q) error column = %d, want %d (%v)", tt.expr, exprErr.Column, tt.column, err)
+			}
+		})
+	}
+}
+
+func TestAttributeExprMatch(t *testing.T) {
+	domain := &router.Domain{
+		Type:  router.Domain_RootDomain,
+		Value: "example.com",
+		Attribute: []*router.Domain_Attribute{
+			{Key: "cn", TypedValue: &router.Domain_Attribute_BoolValue{BoolValue: true}},
+			{Key: "priority", TypedValue: &router.Domain_Attribute_IntValue{IntValue: 3}},
+		},
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{expr: "@cn", want: true},
+		{expr: "@ads", want: false},
+		{expr: "!@ads", want: true},
+		{expr: "@cn && !@ads", want: true},
+		{expr: "@ads || @cn && @priority>5", want: false},
+		{expr: "(@ads || @cn) && @priority<5", want: true},
+		{expr: "!(@ads || @cn)", want: false},
+		{expr: "@priority=3", want: true},
+		{expr: "@priority!=3", want: false},
+		{expr: "@cn>1", want: false},
+	}
+
+	for _, tt := range tests {
+		expr, err := ParseAttributeExpr(tt.expr)
+		if err != nil {
+			t.Fatalf("ParseAttributeExpr(%q) error = %v", tt.expr, err)
+		}
+		if got := expr.Match(domain); got != tt.want {
+			t.Errorf("%s Match() = %t, want %t", tt.expr, got, tt.want)
+		}
+	}
+}
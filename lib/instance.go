@@ -2,6 +2,7 @@ package lib
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -14,6 +15,9 @@ import (
 type Instance struct {
 	Config    *Config
 	Container Container
+	// KeepGoing makes the instance process the remaining inputs and outputs after a plugin fails,
+	// and return all the errors at the end
+	KeepGoing bool
 }
 
 // NewInstance creates a new Instance
@@ -25,6 +29,10 @@ func NewInstance() (*Instance, error) {
 
 // InitConfig initializes the instance with a config file
 func (i *Instance) InitConfig(configFile string) error {
+	return newStageError(StageConfig, i.initConfig(configFile))
+}
+
+func (i *Instance) initConfig(configFile string) error {
 	var configBytes []byte
 	var err error
 
@@ -69,47 +77,159 @@ func (i *Instance) InitConfig(configFile string) error {
 	return nil
 }
 
-// Run runs the conversion process
+// Run runs the conversion process. Transforms and outputs are not processed after an input
+// or a transform fails unless KeepGoing is set.
 func (i *Instance) Run() error {
+	inputErr := i.RunInput()
+	if inputErr != nil && !i.KeepGoing {
+		return inputErr
+	}
+
+	transformErr := i.RunTransform()
+	if transformErr != nil && !i.KeepGoing {
+		return transformErr
+	}
+
+	return errors.Join(inputErr, transformErr, i.RunOutput())
+}
+
+// RunInput processes all inputs into the container
+func (i *Instance) RunInput() error {
 	if i.Config == nil {
-		return fmt.Errorf("config is not initialized")
+		return newStageError(StageConfig, fmt.Errorf("config is not initialized"))
 	}
 
 	// Process input
 	slog.Info("start input processing ...")
+	var errs []error
 	for idx, inputConfig := range i.Config.Input {
 		slog.Debug("processing input ...", "processed", idx+1, "total", len(i.Config.Input), "type", inputConfig.Type, "action", inputConfig.Action)
 
 		converter, err := inputConfig.GetInputConverter()
 		if err != nil {
-			return fmt.Errorf("failed to get input converter: %w", err)
+			err = newStageError(StageConfig, fmt.Errorf("failed to get input converter [type: %s, action: %s]: %w", inputConfig.Type, inputConfig.Action, err))
+			if !i.KeepGoing {
+				return err
+			}
+			slog.Error("input skipped", "err", err)
+			errs = append(errs, err)
+			continue
 		}
 
 		newContainer, err := converter.Input(i.Container)
 		if err != nil {
-			return fmt.Errorf("failed to process input [type: %s, action: %s]: %w", inputConfig.Type, inputConfig.Action, err)
+			err = newStageError(StageInput, fmt.Errorf("failed to process input [type: %s, action: %s]: %w", inputConfig.Type, inputConfig.Action, err))
+			if !i.KeepGoing {
+				return err
+			}
+			slog.Error("input failed", "err", err)
+			errs = append(errs, err)
+			continue
 		}
 
 		if newContainer != nil {
 			i.Container = newContainer
 		}
 	}
+
+	if len(errs) > 0 {
+		slog.Error("input processing completed with errors", "failed", len(errs), "total", len(i.Config.Input))
+		return errors.Join(errs...)
+	}
 	slog.Info("input processing completed")
 
+	return nil
+}
+
+// RunTransform processes all transforms on the container in order
+func (i *Instance) RunTransform() error {
+	if i.Config == nil {
+		return newStageError(StageConfig, fmt.Errorf("config is not initialized"))
+	}
+
+	if len(i.Config.Transform) == 0 {
+		return nil
+	}
+
+	// Process transform
+	slog.Info("start transform processing ...")
+	var errs []error
+	for idx, transformConfig := range i.Config.Transform {
+		slog.Debug("processing transform ...", "processed", idx+1, "total", len(i.Config.Transform), "type", transformConfig.Type, "action", transformConfig.Action)
+
+		converter, err := transformConfig.GetTransformConverter()
+		if err != nil {
+			err = newStageError(StageConfig, fmt.Errorf("failed to get transform converter [type: %s, action: %s]: %w", transformConfig.Type, transformConfig.Action, err))
+			if !i.KeepGoing {
+				return err
+			}
+			slog.Error("transform skipped", "err", err)
+			errs = append(errs, err)
+			continue
+		}
+
+		newContainer, err := converter.Transform(i.Container)
+		if err != nil {
+			err = newStageError(StageTransform, fmt.Errorf("failed to process transform [type: %s, action: %s]: %w", transformConfig.Type, transformConfig.Action, err))
+			if !i.KeepGoing {
+				return err
+			}
+			slog.Error("transform failed", "err", err)
+			errs = append(errs, err)
+			continue
+		}
+
+		if newContainer != nil {
+			i.Container = newContainer
+		}
+	}
+
+	if len(errs) > 0 {
+		slog.Error("transform processing completed with errors", "failed", len(errs), "total", len(i.Config.Transform))
+		return errors.Join(errs...)
+	}
+	slog.Info("transform processing completed")
+
+	return nil
+}
+
+// RunOutput processes all outputs from the container
+func (i *Instance) RunOutput() error {
+	if i.Config == nil {
+		return newStageError(StageConfig, fmt.Errorf("config is not initialized"))
+	}
+
 	// Process output
 	slog.Info("start output processing ...")
+	var errs []error
 	for idx, outputConfig := range i.Config.Output {
-		slog.Debug("processing output ...", "processed", idx+1, "total", len(i.Config.Input), "type", outputConfig.Type, "action", outputConfig.Action)
+		slog.Debug("processing output ...", "processed", idx+1, "total", len(i.Config.Output), "type", outputConfig.Type, "action", outputConfig.Action)
 
 		converter, err := outputConfig.GetOutputConverter()
 		if err != nil {
-			return fmt.Errorf("failed to get output converter: %w", err)
+			err = newStageError(StageConfig, fmt.Errorf("failed to get output converter [type: %s, action: %s]: %w", outputConfig.Type, outputConfig.Action, err))
+			if !i.KeepGoing {
+				return err
+			}
+			slog.Error("output skipped", "err", err)
+			errs = append(errs, err)
+			continue
 		}
 
 		if err := converter.Output(i.Container); err != nil {
-			return fmt.Errorf("failed to process output [type: %s, action: %s]: %w", outputConfig.Type, outputConfig.Action, err)
+			err = newStageError(StageOutput, fmt.Errorf("failed to process output [type: %s, action: %s]: %w", outputConfig.Type, outputConfig.Action, err))
+			if !i.KeepGoing {
+				return err
+			}
+			slog.Error("output failed", "err", err)
+			errs = append(errs, err)
 		}
 	}
+
+	if len(errs) > 0 {
+		slog.Error("output processing completed with errors", "failed", len(errs), "total", len(i.Config.Output))
+		return errors.Join(errs...)
+	}
 	slog.Info("output processing completed")
 
 	return nil
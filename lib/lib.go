@@ -1,20 +1,29 @@
 package lib
 
 const (
-	ActionAdd    Action = "add"
-	ActionRemove Action = "remove"
-	ActionOutput Action = "output"
+	ActionAdd       Action = "add"
+	ActionRemove    Action = "remove"
+	ActionOutput    Action = "output"
+	ActionTransform Action = "transform"
 
 	CaseRemovePrefix CaseRemove = 0
 	CaseRemoveEntry  CaseRemove = 1
 )
 
 var ActionsRegistry = map[Action]bool{
-	ActionAdd:    true,
-	ActionRemove: true,
-	ActionOutput: true,
+	ActionAdd:       true,
+	ActionRemove:    true,
+	ActionOutput:    true,
+	ActionTransform: true,
 }
 
+// Actions supported by the plugins of each stage of a config
+var (
+	InputActions     = []Action{ActionAdd, ActionRemove}
+	TransformActions = []Action{ActionTransform}
+	OutputActions    = []Action{ActionOutput}
+)
+
 type Action string
 
 type CaseRemove int
@@ -38,6 +47,16 @@ type InputConverter interface {
 	Input(Container) (Container, error)
 }
 
+// TransformConverter transforms the lists of a container. Like inputs, transforms modify the container
+// in place and return it. A transform checks everything it needs before modifying the container, so that
+// the container is left unchanged when it fails and the next transforms can run in keep-going mode.
+type TransformConverter interface {
+	Typer
+	Actioner
+	Descriptioner
+	Transform(Container) (Container, error)
+}
+
 type OutputConverter interface {
 	Typer
 	Actioner
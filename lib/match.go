@@ -0,0 +1,58 @@
+package lib
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
+)
+
+// regexpCache holds the compiled regular expressions of regexp rules by pattern,
+// or nil for invalid patterns, so that each pattern is only compiled once
+var regexpCache sync.Map
+
+// compileRegexp returns the cached compiled regular expression of a pattern, or nil if it is invalid
+func compileRegexp(pattern string) *regexp.Regexp {
+	if cached, found := regexpCache.Load(pattern); found {
+		return cached.(*regexp.Regexp)
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		re = nil
+	}
+	regexpCache.Store(pattern, re)
+	return re
+}
+
+// MatchDomain checks if a hostname matches a domain rule, following the semantics of V2Ray:
+// full rules match the hostname exactly, domain rules match the hostname and its subdomains,
+// keyword rules match any hostname containing the value, and regexp rules match by regular expression.
+// The hostname is expected to be lower-cased.
+func MatchDomain(domain *router.Domain, host string) bool {
+	value := domain.GetValue()
+	switch domain.GetType() {
+	case router.Domain_Full:
+		return host == value
+	case router.Domain_RootDomain:
+		return host == value || strings.HasSuffix(host, "."+value)
+	case router.Domain_Plain:
+		return strings.Contains(host, value)
+	case router.Domain_Regex:
+		re := compileRegexp(value)
+		return re != nil && re.MatchString(host)
+	}
+	return false
+}
+
+// MatchEntry returns the domains of an entry matching a hostname
+func MatchEntry(entry *Entry, host string) []*router.Domain {
+	var matched []*router.Domain
+	for _, domain := range entry.GetDomains() {
+		if MatchDomain(domain, host) {
+			matched = append(matched, domain)
+		}
+	}
+	return matched
+}
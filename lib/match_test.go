@@ -0,0 +1,105 @@
+package lib
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
+)
+
+func TestMatchDomain(t *testing.T) {
+	tests := []struct {
+		rule string
+		host string
+		want bool
+	}{
+		// full rules match the domain exactly
+		{rule: "full:example.com", host: "example.com", want: true},
+		{rule: "full:example.com", host: "www.example.com", want: false},
+		{rule: "full:www.example.com", host: "example.com", want: false},
+		{rule: "full:example.com", host: "example.com.cn", want: false},
+
+		// domain rules match the domain and its subdomains
+		{rule: "domain:example.com", host: "example.com", want: true},
+		{rule: "domain:example.com", host: "www.example.com", want: true},
+		{rule: "domain:example.com", host: "a.b.example.com", want: true},
+		{rule: "domain:example.com", host: "notexample.com", want: false},
+		{rule: "domain:example.com", host: "example.com.cn", want: false},
+		{rule: "domain:www.example.com", host: "example.com", want: false},
+
+		// keyword rules match domains containing the value
+		{rule: "keyword:example", host: "example.com", want: true},
+		{rule: "keyword:example", host: "www.myexample.org", want: true},
+		{rule: "keyword:ample.c", host: "example.com", want: true},
+		{rule: "keyword:example", host: "exampl.com", want: false},
+
+		// regexp rules match by regular expression, anywhere in the domain unless anchored
+		{rule: `regexp:^ads?\.`, host: "ads.example.com", want: true},
+		{rule: `regexp:^ads?\.`, host: "ad.example.com", want: true},
+		{rule: `regexp:^ads?\.`, host: "www.ads.example.com", want: false},
+		{rule: `regexp:example`, host: "www.example.com", want: true},
+		{rule: `regexp:\.cn$`, host: "example.com.cn", want: true},
+		{rule: `regexp:\.cn$`, host: "example.cn.com", want: false},
+		{rule: `regexp:(`, host: "(", want: false},
+	}
+
+	for _, tt := range tests {
+		entry := newTestEntry("test", tt.rule)
+		if got := MatchDomain(entry.GetDomains()[0], tt.host); got != tt.want {
+			t.Errorf("MatchDomain(%s, %q) = %v, want %v", tt.rule, tt.host, got, tt.want)
+		}
+	}
+
+	unknown := &router.Domain{Type: router.Domain_Type(-1), Value: "example.com"}
+	if MatchDomain(unknown, "example.com") {
+		t.Errorf("MatchDomain() with an unknown rule type = true, want false")
+	}
+}
+
+func TestCompileRegexp(t *testing.T) {
+	re := compileRegexp(`^cache-test\.`)
+	if re == nil || !re.MatchString("cache-test.example.com") {
+		t.Fatalf("compileRegexp() = %v, want a compiled regular expression", re)
+	}
+	if got := compileRegexp(`^cache-test\.`); got != re {
+		t.Errorf("compileRegexp() = %p on the second call, want the cached %p", got, re)
+	}
+
+	if got := compileRegexp(`cache-test(`); got != nil {
+		t.Errorf("compileRegexp() of an invalid pattern = %v, want nil", got)
+	}
+	if cached, found := regexpCache.Load(`cache-test(`); !found || cached.(*regexp.Regexp) != nil {
+		t.Errorf("regexpCache of an invalid pattern = %v, %v, want a cached nil", cached, found)
+	}
+}
+
+func TestMatchEntry(t *testing.T) {
+	entry := newTestEntry("test",
+		"domain:example.com",
+		"full:www.example.com @cn",
+		"full:example.com",
+		"keyword:ads",
+		`regexp:^www\.`,
+	)
+
+	tests := []struct {
+		host string
+		want []string
+	}{
+		{host: "www.example.com", want: []string{"domain:example.com", "full:www.example.com:@cn", `regexp:^www\.`}},
+		{host: "example.com", want: []string{"domain:example.com", "full:example.com"}},
+		{host: "ads.example.org", want: []string{"keyword:ads"}},
+		{host: "example.org", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		got := make([]string, 0)
+		for _, domain := range MatchEntry(entry, tt.host) {
+			got = append(got, FormatDomain(domain))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("MatchEntry(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+}
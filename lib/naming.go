@@ -0,0 +1,157 @@
+package lib
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+const (
+	MergePolicyMerge   MergePolicy = "merge"
+	MergePolicyReplace MergePolicy = "replace"
+	MergePolicyError   MergePolicy = "error"
+)
+
+// MergePolicy is how an input adds an entry whose name already exists in the container:
+// merge the domains into the existing entry, replace the existing entry, or fail the input
+type MergePolicy string
+
+// ParseMergePolicy parses a merge policy, returning def if s is empty
+func ParseMergePolicy(s string, def MergePolicy) (MergePolicy, error) {
+	policy := MergePolicy(strings.ToLower(strings.TrimSpace(s)))
+	switch policy {
+	case "":
+		return def, nil
+	case MergePolicyMerge, MergePolicyReplace, MergePolicyError:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown merge policy: %s", s)
+	}
+}
+
+// NamingArgs are the arguments of the inputs naming the loaded entries,
+// meant to be embedded in the arguments of an input
+type NamingArgs struct {
+	NamePrefix  string              `json:"namePrefix" desc:"Prefix added to the names of the loaded lists"`
+	Rename      map[string]string   `json:"rename" desc:"New names of the loaded lists, by their original names"`
+	Aliases     map[string][]string `json:"aliases" desc:"Additional names of the loaded lists, by their original names"`
+	MergePolicy string              `json:"mergePolicy" default:"merge" desc:"Handling of lists which already exist: merge, replace or error"`
+}
+
+// EntryNaming names the entries loaded by an input and adds them to a container
+type EntryNaming struct {
+	Prefix  string
+	Rename  map[string]string
+	Aliases map[string][]string
+	Policy  MergePolicy
+}
+
+// EntryNaming validates the naming arguments and creates an EntryNaming from them
+func (a NamingArgs) EntryNaming() (*EntryNaming, error) {
+	policy, err := ParseMergePolicy(a.MergePolicy, MergePolicyMerge)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mergePolicy: %w", err)
+	}
+
+	naming := &EntryNaming{
+		Prefix:  strings.ToUpper(strings.TrimSpace(a.NamePrefix)),
+		Rename:  make(map[string]string, len(a.Rename)),
+		Aliases: make(map[string][]string, len(a.Aliases)),
+		Policy:  policy,
+	}
+
+	for from, to := range a.Rename {
+		from = strings.ToUpper(strings.TrimSpace(from))
+		to = strings.ToUpper(strings.TrimSpace(to))
+		if from == "" || to == "" {
+			return nil, fmt.Errorf("invalid rename %q to %q, names must not be empty", from, to)
+		}
+		naming.Rename[from] = to
+	}
+
+	for name, aliases := range a.Aliases {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if name == "" {
+			return nil, fmt.Errorf("empty list name in aliases")
+		}
+		for _, alias := range aliases {
+			if alias = strings.ToUpper(strings.TrimSpace(alias)); alias == "" {
+				return nil, fmt.Errorf("empty alias of list %s", name)
+			}
+			naming.Aliases[name] = append(naming.Aliases[name], alias)
+		}
+	}
+
+	return naming, nil
+}
+
+// Names returns the names of an entry loaded as name: the new name if renamed, otherwise
+// the name itself, followed by the aliases of the entry, all of them with the prefix
+func (n *EntryNaming) Names(name string) []string {
+	name = strings.ToUpper(strings.TrimSpace(name))
+
+	names := make([]string, 0, 1+len(n.Aliases[name]))
+	if to, found := n.Rename[name]; found {
+		names = append(names, n.Prefix+to)
+	} else {
+		names = append(names, n.Prefix+name)
+	}
+	for _, alias := range n.Aliases[name] {
+		names = append(names, n.Prefix+alias)
+	}
+	return names
+}
+
+// Add adds the domains of the entry to the container under each of its names,
+// following the merge policy when a name already exists in the container
+func (n *EntryNaming) Add(container Container, entry *Entry) error {
+	if entry == nil {
+		return fmt.Errorf("entry is nil")
+	}
+
+	for _, name := range n.Names(entry.GetName()) {
+		named := NewEntry(name)
+		named.AddDomains(entry.GetDomains())
+
+		switch n.Policy {
+		case MergePolicyReplace:
+			if container.Has(name) {
+				slog.Info("replacing existing entry", "name", name)
+			}
+			if err := container.Replace(named); err != nil {
+				return err
+			}
+		case MergePolicyError:
+			if container.Has(name) {
+				return fmt.Errorf("entry %s already exists", name)
+			}
+			if err := container.Add(named); err != nil {
+				return err
+			}
+		default:
+			if err := container.Add(named); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Remove removes the entry from the container under each of its names. See RemoveEntry.
+func (n *EntryNaming) Remove(container Container, entry *Entry, rCase CaseRemove) error {
+	if entry == nil {
+		return fmt.Errorf("entry is nil")
+	}
+
+	for _, name := range n.Names(entry.GetName()) {
+		named := NewEntry(name)
+		named.AddDomains(entry.GetDomains())
+
+		if err := RemoveEntry(container, named, rCase); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Arg describes an argument in the args object of a plugin config
+type Arg struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required,omitempty"`
+	Default     string `json:"default,omitempty"`
+	Description string `json:"description"`
+}
+
+// DescribeArgs describes the arguments of a plugin from the fields of args, a struct whose fields are
+// tagged with their JSON name, their description in desc, and optionally their default value in default
+// and required:"true". Fields of embedded structs are described in place.
+func DescribeArgs(args any) []Arg {
+	result := make([]Arg, 0)
+	for _, field := range argFields(reflect.TypeOf(args)) {
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+
+		arg := Arg{
+			Name:        argName(field),
+			Type:        fieldType.String(),
+			Required:    field.Tag.Get("required") == "true",
+			Default:     field.Tag.Get("default"),
+			Description: field.Tag.Get("desc"),
+		}
+		if arg.Default == "" && fieldType.Kind() == reflect.Bool {
+			arg.Default = "false"
+		}
+		result = append(result, arg)
+	}
+	return result
+}
+
+// UnmarshalArgs unmarshals the args object of a plugin config into args, a pointer to a struct
+// described by DescribeArgs, checks the required arguments and sets the empty ones to their defaults
+func UnmarshalArgs(data json.RawMessage, args any) error {
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, args); err != nil {
+			return err
+		}
+	}
+
+	value := reflect.ValueOf(args).Elem()
+	for _, field := range argFields(value.Type()) {
+		fieldValue := value.FieldByIndex(field.Index)
+		if !isEmptyArg(fieldValue) {
+			continue
+		}
+
+		if field.Tag.Get("required") == "true" {
+			return fmt.Errorf("%s is required", argName(field))
+		}
+		if def, found := field.Tag.Lookup("default"); found {
+			if err := setArgDefault(fieldValue, def); err != nil {
+				return fmt.Errorf("invalid default of %s: %w", argName(field), err)
+			}
+		}
+	}
+	return nil
+}
+
+// argFields returns the fields of an args struct with a JSON name, including those of embedded structs
+func argFields(structType reflect.Type) []reflect.StructField {
+	fields := make([]reflect.StructField, 0, structType.NumField())
+	for _, field := range reflect.VisibleFields(structType) {
+		if field.Anonymous || argName(field) == "" {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// isEmptyArg reports whether an argument is missing from the config, treating empty lists and maps as missing
+func isEmptyArg(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	}
+	return value.IsZero()
+}
+
+func argName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// setArgDefault sets an empty field to its default value in the text form of the default tag.
+// Defaults of slices are comma separated.
+func setArgDefault(value reflect.Value, def string) error {
+	if value.Kind() == reflect.Pointer {
+		value.Set(reflect.New(value.Type().Elem()))
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(def)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		intValue, err := strconv.ParseInt(def, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(intValue)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		uintValue, err := strconv.ParseUint(def, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(uintValue)
+	case reflect.Bool:
+		boolValue, err := strconv.ParseBool(def)
+		if err != nil {
+			return err
+		}
+		value.SetBool(boolValue)
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", value.Type())
+		}
+		value.Set(reflect.ValueOf(strings.Split(def, ",")))
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+	return nil
+}
+
+// ArgsDescriber is implemented by converters which describe their arguments
+type ArgsDescriber interface {
+	GetArgs() []Arg
+}
+
+// ActionSupporter is implemented by converters which support other actions than the default
+// ones, add and remove for inputs, transform for transforms and output for outputs
+type ActionSupporter interface {
+	GetSupportedActions() []Action
+}
+
+// PluginInfo describes a registered converter
+type PluginInfo struct {
+	Type        string   `json:"type"`
+	Description string   `json:"description"`
+	Actions     []Action `json:"actions"`
+	Args        []Arg    `json:"args"`
+}
+
+// ListInputConverters returns the registered input converters sorted by type
+func ListInputConverters() []*PluginInfo {
+	infos := make([]*PluginInfo, 0, len(inputConverterCache))
+	for _, id := range slices.Sorted(maps.Keys(inputConverterCache)) {
+		infos = append(infos, newPluginInfo(id, inputConverterCache[id], InputActions))
+	}
+	return infos
+}
+
+// ListTransformConverters returns the registered transform converters sorted by type
+func ListTransformConverters() []*PluginInfo {
+	infos := make([]*PluginInfo, 0, len(transformConverterCache))
+	for _, id := range slices.Sorted(maps.Keys(transformConverterCache)) {
+		infos = append(infos, newPluginInfo(id, transformConverterCache[id], TransformActions))
+	}
+	return infos
+}
+
+// ListOutputConverters returns the registered output converters sorted by type
+func ListOutputConverters() []*PluginInfo {
+	infos := make([]*PluginInfo, 0, len(outputConverterCache))
+	for _, id := range slices.Sorted(maps.Keys(outputConverterCache)) {
+		infos = append(infos, newPluginInfo(id, outputConverterCache[id], OutputActions))
+	}
+	return infos
+}
+
+// converter is the common part of InputConverter, TransformConverter and OutputConverter
+type converter interface {
+	Typer
+	Descriptioner
+}
+
+func newPluginInfo(id string, converter converter, defaultActions []Action) *PluginInfo {
+	// Types are registered in lower case, so prefer the type of the converter for display
+	if converter.GetType() != "" {
+		id = converter.GetType()
+	}
+
+	info := &PluginInfo{
+		Type:        id,
+		Description: converter.GetDescription(),
+		Actions:     defaultActions,
+	}
+	if supporter, ok := converter.(ActionSupporter); ok {
+		info.Actions = supporter.GetSupportedActions()
+	}
+	if describer, ok := converter.(ArgsDescriber); ok {
+		info.Args = describer.GetArgs()
+	}
+	return info
+}
@@ -0,0 +1,93 @@
+package lib
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type testArgs struct {
+	Name    string   `json:"name" required:"true" desc:"Name"`
+	Dir     string   `json:"dir,omitempty" default:"./output" desc:"Directory"`
+	Count   uint8    `json:"count" default:"2" desc:"Count"`
+	Formats []string `json:"formats" default:"json,srs" desc:"Formats"`
+	Encode  *bool    `json:"encode" default:"true" desc:"Encode"`
+	Verbose bool     `json:"verbose" desc:"Verbose"`
+	Ignored string   `json:"-"`
+	NamingArgs
+}
+
+func TestDescribeArgs(t *testing.T) {
+	got := DescribeArgs(testArgs{})
+
+	want := []Arg{
+		{Name: "name", Type: "string", Required: true, Description: "Name"},
+		{Name: "dir", Type: "string", Default: "./output", Description: "Directory"},
+		{Name: "count", Type: "uint8", Default: "2", Description: "Count"},
+		{Name: "formats", Type: "[]string", Default: "json,srs", Description: "Formats"},
+		{Name: "encode", Type: "bool", Default: "true", Description: "Encode"},
+		{Name: "verbose", Type: "bool", Default: "false", Description: "Verbose"},
+	}
+	if !reflect.DeepEqual(got[:len(want)], want) {
+		t.Errorf("DescribeArgs() = %+v, want %+v", got[:len(want)], want)
+	}
+
+	names := make([]string, 0, len(got)-len(want))
+	for _, arg := range got[len(want):] {
+		names = append(names, arg.Name)
+	}
+	if wantNames := []string{"namePrefix", "rename", "aliases", "mergePolicy"}; !reflect.DeepEqual(names, wantNames) {
+		t.Errorf("DescribeArgs() embedded args = %v, want %v", names, wantNames)
+	}
+}
+
+func TestUnmarshalArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    testArgs
+		wantErr bool
+	}{
+		{
+			name: "defaults",
+			data: `{"name": "a"}`,
+			want: testArgs{Name: "a", Dir: "./output", Count: 2, Formats: []string{"json", "srs"}, Encode: ptr(true), NamingArgs: NamingArgs{MergePolicy: "merge"}},
+		},
+		{
+			name: "empty list gets default",
+			data: `{"name": "a", "formats": []}`,
+			want: testArgs{Name: "a", Dir: "./output", Count: 2, Formats: []string{"json", "srs"}, Encode: ptr(true), NamingArgs: NamingArgs{MergePolicy: "merge"}},
+		},
+		{
+			name: "explicit values",
+			data: `{"name": "a", "dir": "d", "count": 3, "formats": ["json"], "encode": false, "verbose": true, "mergePolicy": "error"}`,
+			want: testArgs{Name: "a", Dir: "d", Count: 3, Formats: []string{"json"}, Encode: ptr(false), Verbose: true, NamingArgs: NamingArgs{MergePolicy: "error"}},
+		},
+		{name: "missing required", data: `{"dir": "d"}`, wantErr: true},
+		{name: "empty config", data: ``, wantErr: true},
+		{name: "invalid json", data: `{"name": 1}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got testArgs
+			err := UnmarshalArgs(json.RawMessage(tt.data), &got)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("UnmarshalArgs(%s) = %+v, want error", tt.data, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UnmarshalArgs(%s) error = %v", tt.data, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UnmarshalArgs(%s) = %+v, want %+v", tt.data, got, tt.want)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,74 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Regex targets are the outputs writing regexp rules, whose consumers support a subset of RE2
+const (
+	RegexTargetRE2     = "re2"
+	RegexTargetSingBox = "singbox"
+	RegexTargetClash   = "clash"
+	RegexTargetGFWList = "gfwlist"
+)
+
+var RegexTargetsRegistry = map[string]bool{
+	RegexTargetRE2:     true,
+	RegexTargetSingBox: true,
+	RegexTargetClash:   true,
+	RegexTargetGFWList: true,
+}
+
+// ValidateRegex compiles pattern with Go's regexp package (RE2), which is used by V2Ray and
+// sing-box, and checks it for constructs that the consumers of the other targets cannot handle
+func ValidateRegex(pattern string, targets ...string) error {
+	if _, err := regexp.Compile(pattern); err != nil {
+		return fmt.Errorf("invalid regular expression for %s: %w", RegexTargetRE2, err)
+	}
+
+	var errs []error
+	for _, target := range targets {
+		switch strings.ToLower(target) {
+		case RegexTargetRE2, RegexTargetSingBox:
+			// Already compiled above
+		case RegexTargetClash:
+			// Clash splits classical rules such as DOMAIN-REGEX,pattern on commas
+			if strings.Contains(pattern, ",") {
+				errs = append(errs, fmt.Errorf("unsupported construct for %s: ','", RegexTargetClash))
+			}
+		case RegexTargetGFWList:
+			// PAC scripts and browser extensions match GFWList regular expressions with JavaScript
+			if construct := findUnsupportedECMAScript(pattern); construct != "" {
+				errs = append(errs, fmt.Errorf("unsupported construct for %s: %s", RegexTargetGFWList, construct))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("unknown regex target: %s", target))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// findUnsupportedECMAScript returns the first RE2 construct in pattern that ECMAScript regular
+// expressions without flags do not support, or an empty string if there is none
+func findUnsupportedECMAScript(pattern string) string {
+	for idx := 0; idx < len(pattern); idx++ {
+		switch {
+		case pattern[idx] == '\\' && idx+1 < len(pattern):
+			switch pattern[idx+1] {
+			case 'A', 'z', 'Q', 'E', 'C', 'p', 'P':
+				return pattern[idx : idx+2]
+			}
+			idx++ // Skip escaped character
+		case strings.HasPrefix(pattern[idx:], "(?P<"):
+			return "(?P<"
+		case strings.HasPrefix(pattern[idx:], "(?") && idx+2 < len(pattern) && !strings.ContainsRune(":<", rune(pattern[idx+2])):
+			return "inline flags (?" + string(pattern[idx+2])
+		case strings.HasPrefix(pattern[idx:], "[[:"):
+			return "POSIX character class [[:"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,92 @@
+package lib
+
+import (
+	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
+)
+
+// ruleIndex indexes the rules of entries to check whether a rule is covered by them
+type ruleIndex struct {
+	rules       map[string]bool
+	rootDomains map[string]bool
+}
+
+func newRuleIndex(entries ...*Entry) *ruleIndex {
+	idx := &ruleIndex{
+		rules:       make(map[string]bool),
+		rootDomains: make(map[string]bool),
+	}
+	for _, entry := range entries {
+		for _, domain := range entry.GetDomains() {
+			idx.rules[domainKey(domain)] = true
+			if domain.GetType() == router.Domain_RootDomain {
+				idx.rootDomains[domain.GetValue()] = true
+			}
+		}
+	}
+	return idx
+}
+
+// covers checks if domain is matched by a rule of the same type and value, or, for full and
+// domain rules, by a domain rule of the same or a parent domain. Attributes are ignored.
+func (idx *ruleIndex) covers(domain *router.Domain) bool {
+	if idx.rules[domainKey(domain)] {
+		return true
+	}
+
+	switch domain.GetType() {
+	case router.Domain_Full, router.Domain_RootDomain:
+		return isCovered(idx.rootDomains, domain.GetValue(), true)
+	}
+	return false
+}
+
+// filter returns the domains covered by the index if covered is true, or not covered otherwise
+func (idx *ruleIndex) filter(domains []*router.Domain, covered bool) []*router.Domain {
+	result := make([]*router.Domain, 0, len(domains))
+	for _, domain := range domains {
+		if idx.covers(domain) == covered {
+			result = append(result, domain)
+		}
+	}
+	return result
+}
+
+// UnionEntries returns an entry named name with the rules of all entries. Duplicated rules and
+// rules covered by a domain rule with the same attributes are removed.
+func UnionEntries(name string, entries ...*Entry) *Entry {
+	result := NewEntry(name)
+	for _, entry := range entries {
+		result.AddDomains(entry.GetDomains())
+	}
+	result.Deduplicate(true)
+	return result
+}
+
+// IntersectEntries returns an entry named name with the rules matching domains matched by all
+// entries, e.g. domain:a.b.com for domain:b.com and domain:a.b.com, or full:x.com for
+// full:x.com and domain:x.com. keyword and regexp rules must be present in all entries.
+func IntersectEntries(name string, entries ...*Entry) *Entry {
+	result := NewEntry(name)
+	if len(entries) == 0 {
+		return result
+	}
+
+	result.AddDomains(entries[0].GetDomains())
+	for _, entry := range entries[1:] {
+		resultIdx, entryIdx := newRuleIndex(result), newRuleIndex(entry)
+		domains := entryIdx.filter(result.GetDomains(), true)
+		domains = append(domains, resultIdx.filter(entry.GetDomains(), true)...)
+		result.Domains = domains
+	}
+	result.Deduplicate(true)
+	return result
+}
+
+// SubtractEntries returns an entry named name with the rules of base which are not covered by
+// any of others, e.g. domain:a.com and full:www.a.com are removed by domain:a.com, but
+// domain:a.com is kept for full:a.com as its subdomains are still matched.
+func SubtractEntries(name string, base *Entry, others ...*Entry) *Entry {
+	result := NewEntry(name)
+	result.AddDomains(newRuleIndex(others...).filter(base.GetDomains(), false))
+	return result
+}
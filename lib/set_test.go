@@ -0,0 +1,179 @@
+package lib
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
+)
+
+var testDomainTypes = map[string]router.Domain_Type{
+	"full":    router.Domain_Full,
+	"domain":  router.Domain_RootDomain,
+	"keyword": router.Domain_Plain,
+	"regexp":  router.Domain_Regex,
+}
+
+// newTestEntry returns an entry with rules like "domain:a.com @ads @cn"
+func newTestEntry(name string, rules ...string) *Entry {
+	entry := NewEntry(name)
+	for _, rule := range rules {
+		fields := strings.Fields(rule)
+		typ, value, _ := strings.Cut(fields[0], ":")
+		domain := newTestDomain(value)
+		domain.Type = testDomainTypes[typ]
+		for _, attr := range fields[1:] {
+			domain.Attribute = append(domain.Attribute, newTestDomain("", strings.TrimPrefix(attr, "@")).Attribute...)
+		}
+		entry.AddDomain(domain)
+	}
+	return entry
+}
+
+// entryRules returns the sorted rules of entry in text format
+func entryRules(entry *Entry) []string {
+	rules := make([]string, 0, len(entry.GetDomains()))
+	for _, domain := range entry.GetDomains() {
+		rules = append(rules, FormatDomain(domain))
+	}
+	slices.Sort(rules)
+	return rules
+}
+
+func TestSetOperations(t *testing.T) {
+	tests := []struct {
+		name  string
+		op    func(entries ...*Entry) *Entry
+		lists [][]string
+		want  []string
+	}{
+		{
+			name: "union removes duplicates and covered rules",
+			op:   func(entries ...*Entry) *Entry { return UnionEntries("result", entries...) },
+			lists: [][]string{
+				{"domain:a.com", "full:b.com"},
+				{"full:www.a.com", "domain:x.a.com", "full:b.com", "keyword:a"},
+			},
+			want: []string{"domain:a.com", "full:b.com", "keyword:a"},
+		},
+		{
+			name: "union keeps rules with other attributes",
+			op:   func(entries ...*Entry) *Entry { return UnionEntries("result", entries...) },
+			lists: [][]string{
+				{"domain:a.com"},
+				{"full:www.a.com @ads"},
+			},
+			want: []string{"domain:a.com", "full:www.a.com:@ads"},
+		},
+		{
+			name: "intersection of a domain and its subdomain",
+			op:   func(entries ...*Entry) *Entry { return IntersectEntries("result", entries...) },
+			lists: [][]string{
+				{"domain:google.com"},
+				{"domain:ads.google.com"},
+			},
+			want: []string{"domain:ads.google.com"},
+		},
+		{
+			name: "intersection of domain and full rules",
+			op:   func(entries ...*Entry) *Entry { return IntersectEntries("result", entries...) },
+			lists: [][]string{
+				{"domain:x.com"},
+				{"full:x.com"},
+			},
+			want: []string{"full:x.com"},
+		},
+		{
+			name: "intersection of unrelated domains",
+			op:   func(entries ...*Entry) *Entry { return IntersectEntries("result", entries...) },
+			lists: [][]string{
+				{"domain:a.com"},
+				{"domain:b.com", "full:a.com.b.com"},
+			},
+			want: []string{},
+		},
+		{
+			name: "intersection requires keyword and regexp rules in every list",
+			op:   func(entries ...*Entry) *Entry { return IntersectEntries("result", entries...) },
+			lists: [][]string{
+				{"keyword:google", "regexp:^ads\\.", "keyword:only-first", "domain:google.com"},
+				{"keyword:google", "regexp:^ads\\.", "regexp:only-second", "keyword:google.com"},
+				{"keyword:google", "regexp:^ads\\.", "keyword:only-first"},
+			},
+			want: []string{"keyword:google", "regexp:^ads\\."},
+		},
+		{
+			name: "intersection ignores attributes when comparing",
+			op:   func(entries ...*Entry) *Entry { return IntersectEntries("result", entries...) },
+			lists: [][]string{
+				{"domain:a.com @cn", "keyword:a @cn"},
+				{"full:www.a.com @ads", "keyword:a @ads"},
+				{"domain:www.a.com", "keyword:a"},
+			},
+			want: []string{"full:www.a.com:@ads", "keyword:a", "keyword:a:@ads", "keyword:a:@cn"},
+		},
+		{
+			name: "difference removes a domain and its subdomains",
+			op:   func(entries ...*Entry) *Entry { return SubtractEntries("result", entries[0], entries[1:]...) },
+			lists: [][]string{
+				{"domain:a.com", "domain:www.a.com", "full:www.a.com", "full:a.com", "domain:b.com"},
+				{"domain:a.com"},
+			},
+			want: []string{"domain:b.com"},
+		},
+		{
+			name: "difference of a full rule keeps the domain rule",
+			op:   func(entries ...*Entry) *Entry { return SubtractEntries("result", entries[0], entries[1:]...) },
+			lists: [][]string{
+				{"domain:a.com", "full:a.com", "full:www.a.com"},
+				{"full:a.com"},
+			},
+			want: []string{"domain:a.com", "full:www.a.com"},
+		},
+		{
+			name: "difference of keyword and regexp rules requires the same rule",
+			op:   func(entries ...*Entry) *Entry { return SubtractEntries("result", entries[0], entries[1:]...) },
+			lists: [][]string{
+				{"keyword:google @ads", "keyword:goo", "regexp:^a", "regexp:^b", "full:google.com"},
+				{"keyword:google"},
+				{"regexp:^a", "keyword:google.com"},
+			},
+			want: []string{"full:google.com", "keyword:goo", "regexp:^b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := make([]*Entry, 0, len(tt.lists))
+			for _, rules := range tt.lists {
+				entries = append(entries, newTestEntry("list", rules...))
+			}
+			if got := entryRules(tt.op(entries...)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetOperationsKeepInputs(t *testing.T) {
+	a := newTestEntry("a", "domain:a.com", "full:www.a.com")
+	b := newTestEntry("b", "domain:www.a.com")
+
+	if got := UnionEntries("union", a, b).GetName(); got != "UNION" {
+		t.Errorf("UnionEntries() name = %q, want %q", got, "UNION")
+	}
+	IntersectEntries("intersect", a, b)
+	SubtractEntries("subtract", a, b)
+
+	if got, want := entryRules(a), []string{"domain:a.com", "full:www.a.com"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("first list changed to %v, want %v", got, want)
+	}
+	if got, want := entryRules(b), []string{"domain:www.a.com"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("second list changed to %v, want %v", got, want)
+	}
+	if got := IntersectEntries("empty").GetDomains(); len(got) != 0 {
+		t.Errorf("IntersectEntries() without lists = %v, want none", got)
+	}
+}
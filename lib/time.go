@@ -0,0 +1,45 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SourceDateEpochEnv is the environment variable of the build timestamp for reproducible builds,
+// see https://reproducible-builds.org/specs/source-date-epoch/
+const SourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
+// ParseTimestamp parses a timestamp in seconds since the Unix epoch or in RFC 3339 format
+func ParseTimestamp(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q, must be seconds since the Unix epoch or in RFC 3339 format", value)
+	}
+	return t, nil
+}
+
+// BuildTime returns the timestamp to embed in generated files. It is the configured value if not empty,
+// then the value of SOURCE_DATE_EPOCH if set, and the current time otherwise.
+func BuildTime(value string) (time.Time, error) {
+	if !IsEmpty(value) {
+		return ParseTimestamp(value)
+	}
+
+	if epoch, found := os.LookupEnv(SourceDateEpochEnv); found && !IsEmpty(epoch) {
+		seconds, err := strconv.ParseInt(strings.TrimSpace(epoch), 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid %s %q: %w", SourceDateEpochEnv, epoch, err)
+		}
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+
+	return time.Now(), nil
+}
@@ -2,9 +2,8 @@ package main
 
 import (
 	"fmt"
-	"log/slog"
+	"strings"
 
-	"github.com/alexxyjiang/domain-list-custom/lib"
 	"github.com/spf13/cobra"
 )
 
@@ -17,38 +16,13 @@ var listCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
 	Short:   "List available domain lists",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// Process inputs and transforms to get the lists seen by the outputs
 		configFile, _ := cmd.Flags().GetString("config")
-		slog.Debug("loading config from", "config", configFile)
-
-		instance, err := lib.NewInstance()
-		if err != nil {
-			slog.Error("failed to create new instance", "err", err)
-		}
-
-		if err := instance.InitConfig(configFile); err != nil {
-			slog.Error("failed to initial config", "err", err)
-		}
-
-		// Process only input to get the list
-		for idx, inputConfig := range instance.Config.Input {
-			slog.Debug("processing input ...", "processed", idx+1, "total", len(instance.Config.Input), "type", inputConfig.Type, "action", inputConfig.Action)
-
-			converter, err := inputConfig.GetInputConverter()
-			if err != nil {
-				slog.Error("failed to get input converter", "type", inputConfig.Type, "err", err)
-			}
-
-			newContainer, err := converter.Input(instance.Container)
-			if err != nil {
-				slog.Error("failed to create input container", "type", inputConfig.Type, "err", err)
-			}
-
-			if newContainer != nil {
-				instance.Container = newContainer
-			}
+		instance, err := loadInstance(configFile)
+		if instance == nil {
+			return err
 		}
-		slog.Debug("all input processors done")
 
 		// List all entries
 		fmt.Println("Available domain lists:", instance.Container.Len(), "in total")
@@ -60,7 +34,12 @@ var listCmd = &cobra.Command{
 			if !found {
 				continue
 			}
-			fmt.Println(" - ", name, "(", len(entry.GetDomains()), "domains)")
+			if attrs := entry.GetAttributes(); len(attrs) > 0 {
+				fmt.Println(" - ", name, "(", len(entry.GetDomains()), "domains)", "attributes: @"+strings.Join(attrs, ", @"))
+			} else {
+				fmt.Println(" - ", name, "(", len(entry.GetDomains()), "domains)")
+			}
 		}
+		return err
 	},
 }
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/alexxyjiang/domain-list-custom/lib"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(lookupCmd)
+	lookupCmd.PersistentFlags().StringP("config", "c", "config.json", "URI of the JSON format config file, whose inputs are loaded")
+	lookupCmd.PersistentFlags().StringP("dat", "d", "", "Path of a V2Ray geosite file to read instead of the config file")
+}
+
+var lookupCmd = &cobra.Command{
+	Use:     "lookup DOMAIN...",
+	Aliases: []string{"match"},
+	Short:   "Find the domain lists and rules matching domains",
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		container, err := loadContainer(cmd)
+		if container == nil {
+			return fmt.Errorf("failed to load domain lists: %w", err)
+		}
+
+		names := container.GetNames()
+		slices.Sort(names)
+
+		for _, host := range args {
+			host = strings.ToLower(strings.TrimSpace(host))
+			if normalized, err := lib.NormalizeDomain(host); err == nil {
+				host = normalized
+			}
+
+			matchedCount := 0
+			fmt.Println(host)
+			for _, name := range names {
+				entry, found := container.GetEntry(name)
+				if !found {
+					continue
+				}
+
+				matched := lib.MatchEntry(entry, host)
+				if len(matched) == 0 {
+					continue
+				}
+				matchedCount++
+
+				fmt.Println(" - ", name)
+				for _, domain := range matched {
+					fmt.Println("     ", lib.FormatDomain(domain))
+				}
+			}
+			fmt.Println("---", matchedCount, "domain lists matched")
+		}
+		return err
+	},
+}
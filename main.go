@@ -1,13 +1,30 @@
 package main
 
 import (
+	"errors"
 	"log/slog"
 	"os"
 
+	"github.com/alexxyjiang/domain-list-custom/lib"
 	"github.com/spf13/cobra"
 )
 
-var verbose bool
+const (
+	// exitCodeDifferent is the exit code of diff --exit-code when there are differences, as with git diff.
+	// It is not used for errors, so that differences can be told from a failing diff.
+	exitCodeDifferent = 1
+	exitCodeConfig    = 2
+	exitCodeInput     = 3
+	exitCodeOutput    = 4
+	exitCodeTransform = 5
+	// exitCodeError is the exit code of invalid arguments and other errors
+	exitCodeError = 6
+)
+
+var (
+	verbose   bool
+	keepGoing bool
+)
 
 var rootCmd = &cobra.Command{
 	Use:   "domain-list-custom",
@@ -15,7 +32,12 @@ var rootCmd = &cobra.Command{
 	CompletionOptions: cobra.CompletionOptions{
 		HiddenDefaultCmd: true,
 	},
+	// Errors are logged by main
+	SilenceErrors: true,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Arguments are valid once here, so errors from now on are not usage errors
+		cmd.SilenceUsage = true
+
 		// Set level based on flag
 		level := slog.LevelInfo
 		if verbose {
@@ -29,10 +51,43 @@ var rootCmd = &cobra.Command{
 
 func init() {
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable debug logging")
+	rootCmd.PersistentFlags().BoolVarP(&keepGoing, "keep-going", "k", false, "Keep processing the remaining inputs, transforms and outputs after a plugin fails, and report all errors at the end")
 }
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
-		slog.Error("exit with error", "err", err)
+		if errors.Is(err, errDifferent) {
+			os.Exit(exitCodeDifferent)
+		}
+
+		// Errors collected in keep-going mode are joined by stage and again by run, so summarize them one by one
+		errs := lib.FlattenErrors(err)
+		for _, err := range errs {
+			if stage := lib.ErrorStage(err); stage != "" {
+				slog.Error("exit with error", "stage", stage, "err", err)
+			} else {
+				slog.Error("exit with error", "err", err)
+			}
+		}
+		if len(errs) > 1 {
+			slog.Error("summary", "errors", len(errs))
+		}
+		os.Exit(exitCode(err))
+	}
+}
+
+// exitCode returns the exit code for the stage where err occurred
+func exitCode(err error) int {
+	switch lib.ErrorStage(err) {
+	case lib.StageConfig:
+		return exitCodeConfig
+	case lib.StageInput:
+		return exitCodeInput
+	case lib.StageTransform:
+		return exitCodeTransform
+	case lib.StageOutput:
+		return exitCodeOutput
+	default:
+		return exitCodeError
 	}
 }
@@ -0,0 +1,208 @@
+package clash
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alexxyjiang/domain-list-custom/lib"
+	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
+)
+
+const (
+	TypeRuleSetOut = "clashRuleSet"
+	DescRuleSetOut = "Convert domain lists to Clash / mihomo rule-provider format"
+)
+
+const (
+	BehaviorDomain    = "domain"
+	BehaviorClassical = "classical"
+
+	FormatYAML = "yaml"
+	FormatText = "text"
+)
+
+func init() {
+	lib.RegisterOutputConfigCreator(TypeRuleSetOut, func(action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
+		return newRuleSetOut(action, data)
+	})
+	lib.RegisterOutputConverter(TypeRuleSetOut, &RuleSetOut{
+		Type:        TypeRuleSetOut,
+		Description: DescRuleSetOut,
+	})
+}
+
+type RuleSetOut struct {
+	Type        string
+	Action      lib.Action
+	Description string
+	OutputDir   string
+	Behavior    string
+	Format      string
+	Want        []string
+	Exclude     []string
+}
+
+type ruleSetOutArgs struct {
+	OutputDir string   `json:"outputDir" default:"./output" desc:"Output directory"`
+	Behavior  string   `json:"behavior" default:"domain" desc:"Behavior of the rule-providers: domain or classical"`
+	Format    string   `json:"format" default:"yaml" desc:"Format of the rule-providers: yaml or text"`
+	Want      []string `json:"wantedList" desc:"Lists to export, all lists if empty"`
+	Exclude   []string `json:"excludedList" desc:"Lists to exclude"`
+}
+
+func newRuleSetOut(action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
+	var tmp ruleSetOutArgs
+	if err := lib.UnmarshalArgs(data, &tmp); err != nil {
+		return nil, err
+	}
+
+	tmp.Behavior = strings.ToLower(strings.TrimSpace(tmp.Behavior))
+	switch tmp.Behavior {
+	case BehaviorDomain, BehaviorClassical:
+	default:
+		return nil, fmt.Errorf("unknown behavior: %s", tmp.Behavior)
+	}
+
+	tmp.Format = strings.ToLower(strings.TrimSpace(tmp.Format))
+	switch tmp.Format {
+	case FormatYAML, FormatText:
+	default:
+		return nil, fmt.Errorf("unknown format: %s", tmp.Format)
+	}
+
+	return &RuleSetOut{
+		Type:        TypeRuleSetOut,
+		Action:      action,
+		Description: DescRuleSetOut,
+		OutputDir:   tmp.OutputDir,
+		Behavior:    tmp.Behavior,
+		Format:      tmp.Format,
+		Want:        tmp.Want,
+		Exclude:     tmp.Exclude,
+	}, nil
+}
+
+func (r *RuleSetOut) GetType() string {
+	return r.Type
+}
+
+func (r *RuleSetOut) GetAction() lib.Action {
+	return r.Action
+}
+
+func (r *RuleSetOut) GetDescription() string {
+	return r.Description
+}
+
+func (r *RuleSetOut) GetArgs() []lib.Arg {
+	return lib.DescribeArgs(ruleSetOutArgs{})
+}
+
+func (r *RuleSetOut) Output(container lib.Container) error {
+	// Create output directory
+	if err := os.MkdirAll(r.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Text rule-providers use .list rather than .txt, which is written by the text output
+	ext := ".yaml"
+	if r.Format == FormatText {
+		ext = ".list"
+	}
+
+	for _, name := range lib.FilterAndSortList(container, r.Want, r.Exclude) {
+		entry, found := container.GetEntry(name)
+		if !found {
+			slog.Debug("❌️ entry not found", "name", name)
+			continue
+		}
+
+		filename := strings.ToLower(entry.GetName()) + ext
+		filepath := filepath.Join(r.OutputDir, filename)
+
+		if err := os.WriteFile(filepath, r.marshal(entry), 0644); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", filepath, err)
+		}
+
+		slog.Info("✅ file generated", "filename", filename)
+	}
+
+	return nil
+}
+
+func (r *RuleSetOut) marshal(entry *lib.Entry) []byte {
+	rules := make([]string, 0, len(entry.GetDomains()))
+	seen := make(map[string]bool, len(entry.GetDomains()))
+	dropped := 0
+
+	for _, domain := range entry.GetDomains() {
+		if r.Behavior == BehaviorClassical && domain.GetType() == router.Domain_Regex {
+			if err := lib.ValidateRegex(domain.GetValue(), lib.RegexTargetClash); err != nil {
+				slog.Warn("invalid regular expression dropped", "name", entry.GetName(), "regexp", domain.GetValue(), "err", err)
+				continue
+			}
+		}
+
+		rule := r.toRule(domain)
+		if rule == "" {
+			dropped++
+			continue
+		}
+		if !seen[rule] {
+			seen[rule] = true
+			rules = append(rules, rule)
+		}
+	}
+
+	if dropped > 0 {
+		slog.Warn("rules not supported by behavior dropped", "name", entry.GetName(), "behavior", r.Behavior, "count", dropped)
+	}
+
+	result := make([]byte, 0, 1024*512)
+	if r.Format == FormatYAML {
+		result = append(result, []byte("payload:\n")...)
+	}
+	for _, rule := range rules {
+		if r.Format == FormatYAML {
+			rule = "  - '" + strings.ReplaceAll(rule, "'", "''") + "'"
+		}
+		result = append(result, []byte(rule+"\n")...)
+	}
+
+	return result
+}
+
+// toRule converts a domain rule to a rule of the behavior, returning an empty
+// string if the behavior cannot express it
+func (r *RuleSetOut) toRule(domain *router.Domain) string {
+	ruleVal := strings.TrimSpace(domain.GetValue())
+	if len(ruleVal) == 0 {
+		return ""
+	}
+
+	if r.Behavior == BehaviorDomain {
+		switch domain.Type {
+		case router.Domain_Full:
+			return ruleVal
+		case router.Domain_RootDomain:
+			return "+." + ruleVal
+		}
+		return ""
+	}
+
+	switch domain.Type {
+	case router.Domain_Full:
+		return "DOMAIN," + ruleVal
+	case router.Domain_RootDomain:
+		return "DOMAIN-SUFFIX," + ruleVal
+	case router.Domain_Plain:
+		return "DOMAIN-KEYWORD," + ruleVal
+	case router.Domain_Regex:
+		return "DOMAIN-REGEX," + ruleVal
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package dns
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/alexxyjiang/domain-list-custom/lib"
+)
+
+const (
+	TypeAdGuardHomeOut = "adguardhome"
+	DescAdGuardHomeOut = "Convert domain lists to AdGuard Home upstream config"
+)
+
+func init() {
+	lib.RegisterOutputConfigCreator(TypeAdGuardHomeOut, func(action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
+		return newAdGuardHomeOut(action, data)
+	})
+	lib.RegisterOutputConverter(TypeAdGuardHomeOut, &AdGuardHomeOut{
+		Type:        TypeAdGuardHomeOut,
+		Description: DescAdGuardHomeOut,
+	})
+}
+
+type AdGuardHomeOut struct {
+	forwarderOut
+	Type        string
+	Action      lib.Action
+	Description string
+	Upstreams   []string
+}
+
+type adGuardHomeOutArgs struct {
+	forwarderArgs
+	Upstreams []string `json:"upstreams" required:"true" desc:"Upstreams of the [/domain/]upstream lines"`
+}
+
+func newAdGuardHomeOut(action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
+	var tmp adGuardHomeOutArgs
+	if err := lib.UnmarshalArgs(data, &tmp); err != nil {
+		return nil, err
+	}
+
+	upstreams := parseServers(tmp.Upstreams)
+	if len(upstreams) == 0 {
+		return nil, fmt.Errorf("upstreams is required")
+	}
+
+	return &AdGuardHomeOut{
+		forwarderOut: forwarderOut{
+			OutputDir: tmp.OutputDir,
+			OutputExt: ".adguard.txt",
+			Want:      tmp.Want,
+			Exclude:   tmp.Exclude,
+		},
+		Type:        TypeAdGuardHomeOut,
+		Action:      action,
+		Description: DescAdGuardHomeOut,
+		Upstreams:   upstreams,
+	}, nil
+}
+
+func (a *AdGuardHomeOut) GetType() string {
+	return a.Type
+}
+
+func (a *AdGuardHomeOut) GetAction() lib.Action {
+	return a.Action
+}
+
+func (a *AdGuardHomeOut) GetDescription() string {
+	return a.Description
+}
+
+func (a *AdGuardHomeOut) GetArgs() []lib.Arg {
+	return lib.DescribeArgs(adGuardHomeOutArgs{})
+}
+
+func (a *AdGuardHomeOut) Output(container lib.Container) error {
+	upstreams := strings.Join(a.Upstreams, " ")
+	return a.output(container, func(domain string) []string {
+		return []string{"[/" + domain + "/]" + upstreams}
+	})
+}
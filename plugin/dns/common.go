@@ -0,0 +1,98 @@
+package dns
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alexxyjiang/domain-list-custom/lib"
+	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
+)
+
+// forwarderOut writes a DNS forwarder config file for each entry. Only full and domain rules
+// can be expressed, both matching the domain and its subdomains in the forwarders. Each forwarder
+// has its own file extension, so that several outputs can share an output directory.
+type forwarderOut struct {
+	OutputDir string
+	OutputExt string
+	Want      []string
+	Exclude   []string
+}
+
+func (f *forwarderOut) output(container lib.Container, toLines func(domain string) []string) error {
+	// Create output directory
+	if err := os.MkdirAll(f.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	for _, name := range lib.FilterAndSortList(container, f.Want, f.Exclude) {
+		entry, found := container.GetEntry(name)
+		if !found {
+			slog.Debug("❌️ entry not found", "name", name)
+			continue
+		}
+
+		filename := strings.ToLower(entry.GetName()) + f.OutputExt
+		filepath := filepath.Join(f.OutputDir, filename)
+
+		if err := os.WriteFile(filepath, marshal(entry, toLines), 0644); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", filepath, err)
+		}
+
+		slog.Info("✅ file generated", "filename", filename)
+	}
+
+	return nil
+}
+
+func marshal(entry *lib.Entry, toLines func(domain string) []string) []byte {
+	result := make([]byte, 0, 1024*512)
+	seen := make(map[string]bool, len(entry.GetDomains()))
+	dropped := 0
+
+	for _, domain := range entry.GetDomains() {
+		ruleVal := strings.TrimSpace(domain.GetValue())
+		if len(ruleVal) == 0 {
+			continue
+		}
+
+		switch domain.Type {
+		case router.Domain_Full, router.Domain_RootDomain:
+			if seen[ruleVal] {
+				continue
+			}
+			seen[ruleVal] = true
+			for _, line := range toLines(ruleVal) {
+				result = append(result, []byte(line+"\n")...)
+			}
+		default:
+			dropped++
+		}
+	}
+
+	if dropped > 0 {
+		slog.Warn("keyword and regexp rules dropped", "name", entry.GetName(), "count", dropped)
+	}
+
+	return result
+}
+
+// parseServers trims the servers and leaves out the empty ones
+func parseServers(servers []string) []string {
+	result := make([]string, 0, len(servers))
+	for _, server := range servers {
+		if server = strings.TrimSpace(server); server != "" {
+			result = append(result, server)
+		}
+	}
+	return result
+}
+
+// forwarderArgs are the arguments shared by the forwarder outputs
+type forwarderArgs struct {
+	OutputDir string   `json:"outputDir" default:"./output" desc:"Output directory"`
+	Want      []string `json:"wantedList" desc:"Lists to export, all lists if empty"`
+	Exclude   []string `json:"excludedList" desc:"Lists to exclude"`
+}
@@ -0,0 +1,100 @@
+package dns
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/alexxyjiang/domain-list-custom/lib"
+)
+
+const (
+	TypeDnsmasqOut = "dnsmasq"
+	DescDnsmasqOut = "Convert domain lists to dnsmasq server, ipset and nftset config"
+)
+
+func init() {
+	lib.RegisterOutputConfigCreator(TypeDnsmasqOut, func(action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
+		return newDnsmasqOut(action, data)
+	})
+	lib.RegisterOutputConverter(TypeDnsmasqOut, &DnsmasqOut{
+		Type:        TypeDnsmasqOut,
+		Description: DescDnsmasqOut,
+	})
+}
+
+type DnsmasqOut struct {
+	forwarderOut
+	Type        string
+	Action      lib.Action
+	Description string
+	Servers     []string
+	IPSet       string
+	NFTSet      string
+}
+
+type dnsmasqOutArgs struct {
+	forwarderArgs
+	Servers []string `json:"servers" desc:"Upstream servers of the server=/domain/server lines"`
+	IPSet   string   `json:"ipset" desc:"Set names of the ipset=/domain/ipset lines"`
+	NFTSet  string   `json:"nftset" desc:"Set specification of the nftset=/domain/nftset lines"`
+}
+
+func newDnsmasqOut(action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
+	var tmp dnsmasqOutArgs
+	if err := lib.UnmarshalArgs(data, &tmp); err != nil {
+		return nil, err
+	}
+
+	servers := parseServers(tmp.Servers)
+	if len(servers) == 0 && tmp.IPSet == "" && tmp.NFTSet == "" {
+		return nil, fmt.Errorf("at least one of servers, ipset and nftset is required")
+	}
+
+	return &DnsmasqOut{
+		forwarderOut: forwarderOut{
+			OutputDir: tmp.OutputDir,
+			OutputExt: ".dnsmasq.conf",
+			Want:      tmp.Want,
+			Exclude:   tmp.Exclude,
+		},
+		Type:        TypeDnsmasqOut,
+		Action:      action,
+		Description: DescDnsmasqOut,
+		Servers:     servers,
+		IPSet:       strings.TrimSpace(tmp.IPSet),
+		NFTSet:      strings.TrimSpace(tmp.NFTSet),
+	}, nil
+}
+
+func (d *DnsmasqOut) GetType() string {
+	return d.Type
+}
+
+func (d *DnsmasqOut) GetAction() lib.Action {
+	return d.Action
+}
+
+func (d *DnsmasqOut) GetDescription() string {
+	return d.Description
+}
+
+func (d *DnsmasqOut) GetArgs() []lib.Arg {
+	return lib.DescribeArgs(dnsmasqOutArgs{})
+}
+
+func (d *DnsmasqOut) Output(container lib.Container) error {
+	return d.output(container, func(domain string) []string {
+		lines := make([]string, 0, len(d.Servers)+2)
+		for _, server := range d.Servers {
+			lines = append(lines, "server=/"+domain+"/"+server)
+		}
+		if d.IPSet != "" {
+			lines = append(lines, "ipset=/"+domain+"/"+d.IPSet)
+		}
+		if d.NFTSet != "" {
+			lines = append(lines, "nftset=/"+domain+"/"+d.NFTSet)
+		}
+		return lines
+	})
+}
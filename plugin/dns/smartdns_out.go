@@ -0,0 +1,83 @@
+package dns
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/alexxyjiang/domain-list-custom/lib"
+)
+
+const (
+	TypeSmartDNSOut = "smartdns"
+	DescSmartDNSOut = "Convert domain lists to SmartDNS nameserver config"
+)
+
+func init() {
+	lib.RegisterOutputConfigCreator(TypeSmartDNSOut, func(action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
+		return newSmartDNSOut(action, data)
+	})
+	lib.RegisterOutputConverter(TypeSmartDNSOut, &SmartDNSOut{
+		Type:        TypeSmartDNSOut,
+		Description: DescSmartDNSOut,
+	})
+}
+
+type SmartDNSOut struct {
+	forwarderOut
+	Type        string
+	Action      lib.Action
+	Description string
+	Group       string
+}
+
+type smartDNSOutArgs struct {
+	forwarderArgs
+	Group string `json:"group" required:"true" desc:"Server group of the nameserver /domain/group lines"`
+}
+
+func newSmartDNSOut(action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
+	var tmp smartDNSOutArgs
+	if err := lib.UnmarshalArgs(data, &tmp); err != nil {
+		return nil, err
+	}
+
+	if tmp.Group = strings.TrimSpace(tmp.Group); tmp.Group == "" {
+		return nil, fmt.Errorf("group is required")
+	}
+
+	return &SmartDNSOut{
+		forwarderOut: forwarderOut{
+			OutputDir: tmp.OutputDir,
+			OutputExt: ".smartdns.conf",
+			Want:      tmp.Want,
+			Exclude:   tmp.Exclude,
+		},
+		Type:        TypeSmartDNSOut,
+		Action:      action,
+		Description: DescSmartDNSOut,
+		Group:       tmp.Group,
+	}, nil
+}
+
+func (s *SmartDNSOut) GetType() string {
+	return s.Type
+}
+
+func (s *SmartDNSOut) GetAction() lib.Action {
+	return s.Action
+}
+
+func (s *SmartDNSOut) GetDescription() string {
+	return s.Description
+}
+
+func (s *SmartDNSOut) GetArgs() []lib.Arg {
+	return lib.DescribeArgs(smartDNSOutArgs{})
+}
+
+func (s *SmartDNSOut) Output(container lib.Container) error {
+	return s.output(container, func(domain string) []string {
+		return []string{"nameserver /" + domain + "/" + s.Group}
+	})
+}
@@ -0,0 +1,190 @@
+package plaintext
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/alexxyjiang/domain-list-custom/lib"
+	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
+)
+
+const (
+	TypeAdblockIn = "adblock"
+	DescAdblockIn = "Convert Adblock Plus / uBlock Origin domain filters to other formats"
+)
+
+func init() {
+	lib.RegisterInputConfigCreator(TypeAdblockIn, func(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
+		return newAdblockIn(action, data)
+	})
+	lib.RegisterInputConverter(TypeAdblockIn, &AdblockIn{
+		Type:        TypeAdblockIn,
+		Description: DescAdblockIn,
+	})
+}
+
+type AdblockIn struct {
+	Type        string
+	Action      lib.Action
+	Description string
+	Name        string
+	InputFile   string
+	Attributes  []*router.Domain_Attribute
+	DomainCheck lib.CheckMode
+	Naming      *lib.EntryNaming
+}
+
+type adblockInArgs struct {
+	Name        string   `json:"name" required:"true" desc:"Name of the list to load the rules into"`
+	InputFile   string   `json:"inputFile" required:"true" desc:"Path of the filter list"`
+	Attributes  []string `json:"attributes" desc:"Attributes attached to every rule, e.g. @ads"`
+	DomainCheck string   `json:"domainCheck" default:"skip" desc:"Handling of invalid domains: error, warn or skip"`
+	lib.NamingArgs
+}
+
+func newAdblockIn(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
+	var tmp adblockInArgs
+	if err := lib.UnmarshalArgs(data, &tmp); err != nil {
+		return nil, err
+	}
+
+	if action != lib.ActionAdd && action != lib.ActionRemove {
+		return nil, fmt.Errorf("unsupported action: %s", action)
+	}
+
+	if strings.TrimSpace(tmp.Name) == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	attrs, err := parseAttributeArgs(tmp.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	domainCheck, err := lib.ParseCheckMode(tmp.DomainCheck, lib.CheckModeSkip)
+	if err != nil {
+		return nil, fmt.Errorf("invalid domainCheck: %w", err)
+	}
+
+	naming, err := tmp.NamingArgs.EntryNaming()
+	if err != nil {
+		return nil, err
+	}
+
+	return &AdblockIn{
+		Type:        TypeAdblockIn,
+		Action:      action,
+		Description: DescAdblockIn,
+		Name:        tmp.Name,
+		InputFile:   tmp.InputFile,
+		Attributes:  attrs,
+		DomainCheck: domainCheck,
+		Naming:      naming,
+	}, nil
+}
+
+func (a *AdblockIn) GetType() string {
+	return a.Type
+}
+
+func (a *AdblockIn) GetAction() lib.Action {
+	return a.Action
+}
+
+func (a *AdblockIn) GetDescription() string {
+	return a.Description
+}
+
+func (a *AdblockIn) GetArgs() []lib.Arg {
+	return lib.DescribeArgs(adblockInArgs{})
+}
+
+func (a *AdblockIn) Input(container lib.Container) (lib.Container, error) {
+	file, err := os.Open(a.InputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", a.InputFile, err)
+	}
+	defer file.Close()
+
+	blocked := lib.NewEntry(a.Name)
+	exceptions := lib.NewEntry(a.Name)
+	unsupported, withOptions := 0, 0
+
+	lineNum := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineNum++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '!' || line[0] == '[' {
+			// Skip empty lines, comments and headers
+			continue
+		}
+
+		target := blocked
+		column := 1
+		if rest, found := strings.CutPrefix(line, "@@"); found {
+			target, line, column = exceptions, rest, column+2
+		}
+
+		// Filters with options, e.g. ||example.com^$third-party, only apply to some requests
+		if strings.Contains(line, "$") {
+			withOptions++
+			continue
+		}
+
+		// Only domain filters in the form of ||example.com^ are supported
+		value, found := strings.CutPrefix(line, "||")
+		if !found || !strings.HasSuffix(value, "^") {
+			unsupported++
+			continue
+		}
+		value = strings.TrimSuffix(value, "^")
+		if strings.ContainsAny(value, "/*|") {
+			unsupported++
+			continue
+		}
+
+		domain, checkErr := newCheckedDomain(router.Domain_RootDomain, token{value: value, column: column + 2}, a.Attributes)
+		if checkErr != nil {
+			checkErr.File, checkErr.Line = a.InputFile, lineNum
+		}
+		if keep, err := checkDomain(checkErr, a.DomainCheck); err != nil {
+			return nil, err
+		} else if keep {
+			target.AddDomain(domain)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", a.InputFile, err)
+	}
+
+	if withOptions > 0 {
+		slog.Warn("filters with options skipped", "file", a.InputFile, "count", withOptions)
+	}
+	if unsupported > 0 {
+		slog.Warn("unsupported filters skipped", "file", a.InputFile, "count", unsupported)
+	}
+
+	switch a.Action {
+	case lib.ActionAdd:
+		if err := a.Naming.Add(container, blocked); err != nil {
+			return nil, err
+		}
+		// Exception rules unblock domains, so they are removed from the entry
+		if err := a.Naming.Remove(container, exceptions, lib.CaseRemovePrefix); err != nil {
+			return nil, err
+		}
+	case lib.ActionRemove:
+		if err := a.Naming.Remove(container, blocked, lib.CaseRemovePrefix); err != nil {
+			return nil, err
+		}
+	}
+
+	return container, nil
+}
@@ -3,9 +3,13 @@ package plaintext
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/alexxyjiang/domain-list-custom/lib"
@@ -22,6 +26,7 @@ func init() {
 		return newDomainListIn(action, data)
 	})
 	lib.RegisterInputConverter(TypeDomainListIn, &DomainListIn{
+		Type:        TypeDomainListIn,
 		Description: DescDomainListIn,
 	})
 }
@@ -32,29 +37,89 @@ type DomainListIn struct {
 	Description string
 	DataDir     string
 	Want        map[string]bool
+	RemoveCase  lib.CaseRemove
+	RegexCheck  lib.CheckMode
+	RegexTarget []string
+	DomainCheck lib.CheckMode
+	Naming      *lib.EntryNaming
 }
 
 type fileInfo struct {
 	Name                  string
 	HasInclusion          bool
-	InclusionAttributeMap map[string][]string
+	InclusionAttributeMap map[string][]*inclusionFilter
 	Domains               []*router.Domain
 }
 
-func newDomainListIn(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
-	var tmp struct {
-		DataDir string   `json:"dataDir"`
-		Want    []string `json:"wantedList"`
+// inclusionFilter selects the domains of an included file which have any of the wanted attributes
+// and none of the unwanted attributes, e.g. include:google @cn @-ads, and which match the
+// attribute expression if any, e.g. include:google @cn && !(@ads || @!cn)
+type inclusionFilter struct {
+	Want    []string
+	NotWant []string
+	Expr    lib.AttributeExpr
+}
+
+func (f *inclusionFilter) match(domain *router.Domain) bool {
+	if f.Expr != nil && !f.Expr.Match(domain) {
+		return false
+	}
+
+	attrs := make(map[string]bool, len(domain.GetAttribute()))
+	for _, attr := range domain.GetAttribute() {
+		attrs[attr.GetKey()] = true
 	}
 
-	if len(data) > 0 {
-		if err := json.Unmarshal(data, &tmp); err != nil {
-			return nil, err
+	if len(f.Want) > 0 && !slices.ContainsFunc(f.Want, func(attr string) bool { return attrs[attr] }) {
+		return false
+	}
+	for _, attr := range f.NotWant {
+		if attrs[attr] {
+			return false
 		}
 	}
+	return true
+}
+
+type domainListInArgs struct {
+	DataDir     string   `json:"dataDir" required:"true" desc:"Directory of the domain list files"`
+	Want        []string `json:"wantedList" desc:"Lists to load, all lists if empty"`
+	RemoveEntry bool     `json:"removeEntry" desc:"Remove whole lists instead of their rules with the remove action"`
+	RegexCheck  string   `json:"regexCheck" default:"warn" desc:"Handling of invalid regular expressions: error, warn or skip"`
+	RegexTarget []string `json:"regexTargets" desc:"Outputs the regular expressions must be compatible with: singbox, clash or gfwlist"`
+	DomainCheck string   `json:"domainCheck" default:"warn" desc:"Handling of invalid domains: error, warn or skip"`
+	lib.NamingArgs
+}
+
+func newDomainListIn(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
+	var tmp domainListInArgs
+	if err := lib.UnmarshalArgs(data, &tmp); err != nil {
+		return nil, err
+	}
+
+	if action != lib.ActionAdd && action != lib.ActionRemove {
+		return nil, fmt.Errorf("unsupported action: %s", action)
+	}
+
+	removeCase := lib.CaseRemovePrefix
+	if tmp.RemoveEntry {
+		removeCase = lib.CaseRemoveEntry
+	}
+
+	regexCheck, err := lib.ParseCheckMode(tmp.RegexCheck, lib.CheckModeWarn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regexCheck: %w", err)
+	}
+
+	domainCheck, err := lib.ParseCheckMode(tmp.DomainCheck, lib.CheckModeWarn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid domainCheck: %w", err)
+	}
 
-	if tmp.DataDir == "" {
-		return nil, fmt.Errorf("dataDir is required")
+	for _, target := range tmp.RegexTarget {
+		if !lib.RegexTargetsRegistry[strings.ToLower(target)] {
+			return nil, fmt.Errorf("unknown regex target: %s", target)
+		}
 	}
 
 	// Filter wanted list
@@ -65,12 +130,22 @@ func newDomainListIn(action lib.Action, data json.RawMessage) (lib.InputConverte
 		}
 	}
 
+	naming, err := tmp.NamingArgs.EntryNaming()
+	if err != nil {
+		return nil, err
+	}
+
 	return &DomainListIn{
 		Type:        TypeDomainListIn,
 		Action:      action,
 		Description: DescDomainListIn,
 		DataDir:     tmp.DataDir,
 		Want:        wantList,
+		RemoveCase:  removeCase,
+		RegexCheck:  regexCheck,
+		RegexTarget: tmp.RegexTarget,
+		DomainCheck: domainCheck,
+		Naming:      naming,
 	}, nil
 }
 
@@ -86,6 +161,10 @@ func (d *DomainListIn) GetDescription() string {
 	return d.Description
 }
 
+func (d *DomainListIn) GetArgs() []lib.Arg {
+	return lib.DescribeArgs(domainListInArgs{})
+}
+
 func (d *DomainListIn) Input(container lib.Container) (lib.Container, error) {
 	// Read all files from data directory
 	fileInfoMap := make(map[string]*fileInfo)
@@ -107,7 +186,7 @@ func (d *DomainListIn) Input(container lib.Container) (lib.Container, error) {
 
 		fileData, err := d.processFile(path, filename)
 		if err != nil {
-			return fmt.Errorf("failed to process file %s: %w", path, err)
+			return err
 		}
 
 		fileInfoMap[filename] = fileData
@@ -123,13 +202,20 @@ func (d *DomainListIn) Input(container lib.Container) (lib.Container, error) {
 		return nil, err
 	}
 
-	// Add entries to container
-	for filename, fileData := range fileInfoMap {
+	// Add entries to or remove entries from container
+	for _, filename := range slices.Sorted(maps.Keys(fileInfoMap)) {
 		entry := lib.NewEntry(filename)
-		entry.AddDomains(fileData.Domains)
+		entry.AddDomains(fileInfoMap[filename].Domains)
 
-		if err := container.Add(entry); err != nil {
-			return nil, err
+		switch d.Action {
+		case lib.ActionAdd:
+			if err := d.Naming.Add(container, entry); err != nil {
+				return nil, err
+			}
+		case lib.ActionRemove:
+			if err := d.Naming.Remove(container, entry, d.RemoveCase); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -139,148 +225,174 @@ func (d *DomainListIn) Input(container lib.Container) (lib.Container, error) {
 func (d *DomainListIn) processFile(path string, filename string) (*fileInfo, error) {
 	file, err := os.Open(path)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to open file %s: %w", path, err)
 	}
 	defer file.Close()
 
 	info := &fileInfo{
 		Name:                  filename,
-		InclusionAttributeMap: make(map[string][]string),
+		InclusionAttributeMap: make(map[string][]*inclusionFilter),
 		Domains:               make([]*router.Domain, 0),
 	}
 
+	lineNum := 0
 	scanner := bufio.NewScanner(file)
 	for scanner.Scan() {
-		line := scanner.Text()
+		lineNum++
 
-		if lib.IsEmpty(line) {
+		tokens := tokenize(scanner.Text())
+		if len(tokens) == 0 {
 			continue
 		}
 
-		line = lib.RemoveComment(line)
-		if lib.IsEmpty(line) {
-			continue
+		// Parse rule
+		domain, isInclusion, parseErr := d.parseRule(scanner.Text(), tokens, info)
+		if parseErr != nil {
+			parseErr.File = path
+			parseErr.Line = lineNum
+			return nil, parseErr
 		}
 
-		// Parse rule
-		domain, isInclusion, err := d.parseRule(line, info)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse rule '%s': %w", line, err)
+		if isInclusion || domain == nil {
+			continue
 		}
 
-		if !isInclusion && domain != nil {
-			info.Domains = append(info.Domains, domain)
+		// Validate rule
+		if checkErr, mode := d.validateRule(domain, tokens[0]); checkErr != nil {
+			checkErr.File = path
+			checkErr.Line = lineNum
+			switch mode {
+			case lib.CheckModeError:
+				return nil, checkErr
+			case lib.CheckModeWarn:
+				slog.Warn("invalid rule", "err", checkErr)
+			case lib.CheckModeSkip:
+				slog.Warn("skipping invalid rule", "err", checkErr)
+				continue
+			}
 		}
+
+		info.Domains = append(info.Domains, domain)
 	}
 
 	if err := scanner.Err(); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
 	}
 
 	return info, nil
 }
 
-func (d *DomainListIn) parseRule(line string, info *fileInfo) (*router.Domain, bool, error) {
-	line = strings.TrimSpace(line)
-
-	if line == "" {
-		return nil, false, fmt.Errorf("empty line")
-	}
-
+func (d *DomainListIn) parseRule(line string, tokens []token, info *fileInfo) (*router.Domain, bool, *ParseError) {
 	// Parse include rule
-	if strings.HasPrefix(line, "include:") {
-		d.parseInclusion(line, info)
-		return nil, true, nil
+	if strings.HasPrefix(tokens[0].value, "include:") {
+		return nil, true, d.parseInclusion(line, tokens, info)
 	}
 
-	parts := strings.Split(line, " ")
-	ruleWithType := strings.TrimSpace(parts[0])
-	if ruleWithType == "" {
-		return nil, false, fmt.Errorf("empty rule")
-	}
-
-	var domain router.Domain
-	if err := d.parseTypeRule(ruleWithType, &domain); err != nil {
+	domain, err := parseTypeRule(tokens[0])
+	if err != nil {
 		return nil, false, err
 	}
 
 	// Parse attributes
-	for _, attrString := range parts[1:] {
-		if attrString = strings.TrimSpace(attrString); attrString != "" {
-			attr, err := d.parseAttribute(attrString)
-			if err != nil {
-				return nil, false, err
-			}
-			domain.Attribute = append(domain.Attribute, attr)
+	for _, tok := range tokens[1:] {
+		attr, err := parseAttribute(tok)
+		if err != nil {
+			return nil, false, err
 		}
+		domain.Attribute = append(domain.Attribute, attr)
 	}
 
-	return &domain, false, nil
+	return domain, false, nil
 }
 
-func (d *DomainListIn) parseInclusion(inclusion string, info *fileInfo) {
-	inclusionVal := strings.TrimPrefix(strings.TrimSpace(inclusion), "include:")
+// validateRule validates and normalizes a parsed rule, and returns the validation error
+// located at the rule value with the check mode to apply
+func (d *DomainListIn) validateRule(domain *router.Domain, tok token) (*ParseError, lib.CheckMode) {
+	switch domain.GetType() {
+	case router.Domain_Full, router.Domain_RootDomain:
+		value := domain.GetValue()
+		if domain.GetType() == router.Domain_RootDomain {
+			// Wildcard labels are redundant in domain rules, e.g. domain:*.example.com
+			value = strings.TrimPrefix(value, "*.")
+		}
+
+		normalized, err := lib.NormalizeDomain(value)
+		if err != nil {
+			return wrapParseError(valueColumn(tok), err), d.DomainCheck
+		}
+		domain.Value = normalized
+	case router.Domain_Regex:
+		if err := lib.ValidateRegex(domain.GetValue(), d.RegexTarget...); err != nil {
+			return wrapParseError(valueColumn(tok), err), d.RegexCheck
+		}
+	}
+	return nil, ""
+}
+
+func (d *DomainListIn) parseInclusion(line string, tokens []token, info *fileInfo) *ParseError {
+	// Attributes may follow the file name directly, e.g. include:google@cn
+	inclusionVal := strings.TrimPrefix(tokens[0].value, "include:")
+	filename, inlineAttrs, _ := strings.Cut(inclusionVal, "@")
+	if filename == "" {
+		return newParseError(tokens[0].column, "empty file name in inclusion")
+	}
+	filename = strings.ToUpper(filename)
 	info.HasInclusion = true
 
-	inclusionValSlice := strings.Split(inclusionVal, "@")
-	filename := strings.ToUpper(strings.TrimSpace(inclusionValSlice[0]))
-
-	switch len(inclusionValSlice) {
-	case 1:
-		// Inclusion without attribute
-		info.InclusionAttributeMap[filename] = append(info.InclusionAttributeMap[filename], "@")
-	default:
-		// Inclusion with attributes
-		for _, attr := range inclusionValSlice[1:] {
-			attr = strings.ToLower(strings.TrimSpace(attr))
-			if attr != "" {
-				info.InclusionAttributeMap[filename] = append(info.InclusionAttributeMap[filename], "@"+attr)
+	attrs := make([]string, 0, len(tokens))
+	for _, attr := range strings.Split(inlineAttrs, "@") {
+		if attr = strings.ToLower(attr); attr != "" {
+			attrs = append(attrs, attr)
+		}
+	}
+
+	filter := new(inclusionFilter)
+	if isAttributeExpr(tokens[1:]) {
+		// The rest of the line is an attribute expression
+		expr, err := lib.ParseAttributeExpr(textFrom(line, tokens[1].column))
+		if err != nil {
+			var exprErr *lib.ExprError
+			if errors.As(err, &exprErr) {
+				return newParseError(tokens[1].column+exprErr.Column-1, "invalid attribute expression: %s", exprErr.Msg)
 			}
+			return wrapParseError(tokens[1].column, err)
+		}
+		filter.Expr = expr
+	} else {
+		for _, tok := range tokens[1:] {
+			attr, err := parseAttribute(tok)
+			if err != nil {
+				return err
+			}
+			attrs = append(attrs, attr.GetKey())
 		}
 	}
-}
 
-func (d *DomainListIn) parseTypeRule(domain string, rule *router.Domain) error {
-	kv := strings.Split(domain, ":")
-	switch len(kv) {
-	case 1:
-		// Line without type prefix
-		rule.Type = router.Domain_RootDomain
-		rule.Value = strings.ToLower(strings.TrimSpace(kv[0]))
-	case 2:
-		// Line with type prefix
-		ruleType := strings.TrimSpace(kv[0])
-		ruleVal := strings.TrimSpace(kv[1])
-		rule.Value = strings.ToLower(ruleVal)
-
-		switch strings.ToLower(ruleType) {
-		case "full":
-			rule.Type = router.Domain_Full
-		case "domain":
-			rule.Type = router.Domain_RootDomain
-		case "keyword":
-			rule.Type = router.Domain_Plain
-		case "regexp":
-			rule.Type = router.Domain_Regex
-			rule.Value = ruleVal // Keep original case for regex
-		default:
-			return fmt.Errorf("unknown domain type: %s", ruleType)
+	// Attributes prefixed with '-' must not be present, e.g. @-cn
+	for _, attr := range attrs {
+		if notWant, found := strings.CutPrefix(attr, "-"); found {
+			if notWant == "" {
+				return newParseError(tokens[0].column, "empty negated attribute in inclusion")
+			}
+			filter.NotWant = append(filter.NotWant, notWant)
+		} else {
+			filter.Want = append(filter.Want, attr)
 		}
 	}
+	info.InclusionAttributeMap[filename] = append(info.InclusionAttributeMap[filename], filter)
+
 	return nil
 }
 
-func (d *DomainListIn) parseAttribute(attr string) (*router.Domain_Attribute, error) {
-	if attr[0] != '@' {
-		return nil, fmt.Errorf("invalid attribute: %s", attr)
+// isAttributeExpr checks if the tokens following an inclusion form an attribute expression
+// rather than a list of attributes, e.g. !@ads or (@cn || @ads)
+func isAttributeExpr(tokens []token) bool {
+	for _, tok := range tokens {
+		if strings.HasPrefix(tok.value, "!") || strings.ContainsAny(tok.value, "()&|") {
+			return true
+		}
 	}
-	attr = attr[1:] // Trim attribute prefix '@'
-
-	var attribute router.Domain_Attribute
-	attribute.Key = strings.ToLower(attr)
-	attribute.TypedValue = &router.Domain_Attribute_BoolValue{BoolValue: true}
-
-	return &attribute, nil
+	return false
 }
 
 func (d *DomainListIn) processInclusions(fileInfoMap map[string]*fileInfo) error {
@@ -290,7 +402,8 @@ func (d *DomainListIn) processInclusions(fileInfoMap map[string]*fileInfo) error
 	for len(processed) < len(fileInfoMap) {
 		changed := false
 
-		for filename, info := range fileInfoMap {
+		for _, filename := range slices.Sorted(maps.Keys(fileInfoMap)) {
+			info := fileInfoMap[filename]
 			if processed[filename] {
 				continue
 			}
@@ -309,25 +422,20 @@ func (d *DomainListIn) processInclusions(fileInfoMap map[string]*fileInfo) error
 			if canProcess || !info.HasInclusion {
 				// Process inclusions
 				if info.HasInclusion {
-					for depName, attrs := range info.InclusionAttributeMap {
+					// Include files in the order of their names so that the rules come out in a stable order
+					for _, depName := range slices.Sorted(maps.Keys(info.InclusionAttributeMap)) {
+						filters := info.InclusionAttributeMap[depName]
 						depInfo := fileInfoMap[depName]
 						if depInfo == nil {
 							return fmt.Errorf("included file %s not found", depName)
 						}
 
-						for _, attrWanted := range attrs {
-							if attrWanted == "@" {
-								// Include all domains
-								info.Domains = append(info.Domains, depInfo.Domains...)
-							} else {
-								// Include domains with specific attribute
-								for _, domain := range depInfo.Domains {
-									for _, attr := range domain.Attribute {
-										if "@"+attr.GetKey() == attrWanted {
-											info.Domains = append(info.Domains, domain)
-											break
-										}
-									}
+						// Include domains matching any of the filters
+						for _, domain := range depInfo.Domains {
+							for _, filter := range filters {
+								if filter.match(domain) {
+									info.Domains = append(info.Domains, domain)
+									break
 								}
 							}
 						}
@@ -342,7 +450,7 @@ func (d *DomainListIn) processInclusions(fileInfoMap map[string]*fileInfo) error
 		if !changed {
 			// Circular dependency detected
 			var unprocessed []string
-			for filename := range fileInfoMap {
+			for _, filename := range slices.Sorted(maps.Keys(fileInfoMap)) {
 				if !processed[filename] {
 					unprocessed = append(unprocessed, filename)
 				}
@@ -0,0 +1,261 @@
+package plaintext
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/alexxyjiang/domain-list-custom/lib"
+	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
+)
+
+const (
+	TypeGFWListIn = "gfwlist"
+	DescGFWListIn = "Convert GFWList (AutoProxy) format to other formats"
+)
+
+func init() {
+	lib.RegisterInputConfigCreator(TypeGFWListIn, func(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
+		return newGFWListIn(action, data)
+	})
+	lib.RegisterInputConverter(TypeGFWListIn, &GFWListIn{
+		Type:        TypeGFWListIn,
+		Description: DescGFWListIn,
+	})
+}
+
+type GFWListIn struct {
+	Type        string
+	Action      lib.Action
+	Description string
+	Name        string
+	InputFile   string
+	Attributes  []*router.Domain_Attribute
+	DomainCheck lib.CheckMode
+	Naming      *lib.EntryNaming
+}
+
+type gfwListInArgs struct {
+	Name        string   `json:"name" required:"true" desc:"Name of the list to load the rules into"`
+	InputFile   string   `json:"inputFile" required:"true" desc:"Path of the GFWList file, base64 encoded or not"`
+	Attributes  []string `json:"attributes" desc:"Attributes attached to every rule, e.g. @ads"`
+	DomainCheck string   `json:"domainCheck" default:"skip" desc:"Handling of invalid domains: error, warn or skip"`
+	lib.NamingArgs
+}
+
+func newGFWListIn(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
+	var tmp gfwListInArgs
+	if err := lib.UnmarshalArgs(data, &tmp); err != nil {
+		return nil, err
+	}
+
+	if action != lib.ActionAdd && action != lib.ActionRemove {
+		return nil, fmt.Errorf("unsupported action: %s", action)
+	}
+
+	if strings.TrimSpace(tmp.Name) == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	attrs, err := parseAttributeArgs(tmp.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	domainCheck, err := lib.ParseCheckMode(tmp.DomainCheck, lib.CheckModeSkip)
+	if err != nil {
+		return nil, fmt.Errorf("invalid domainCheck: %w", err)
+	}
+
+	naming, err := tmp.NamingArgs.EntryNaming()
+	if err != nil {
+		return nil, err
+	}
+
+	return &GFWListIn{
+		Type:        TypeGFWListIn,
+		Action:      action,
+		Description: DescGFWListIn,
+		Name:        tmp.Name,
+		InputFile:   tmp.InputFile,
+		Attributes:  attrs,
+		DomainCheck: domainCheck,
+		Naming:      naming,
+	}, nil
+}
+
+func (g *GFWListIn) GetType() string {
+	return g.Type
+}
+
+func (g *GFWListIn) GetAction() lib.Action {
+	return g.Action
+}
+
+func (g *GFWListIn) GetDescription() string {
+	return g.Description
+}
+
+func (g *GFWListIn) GetArgs() []lib.Arg {
+	return lib.DescribeArgs(gfwListInArgs{})
+}
+
+func (g *GFWListIn) Input(container lib.Container) (lib.Container, error) {
+	data, err := os.ReadFile(g.InputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", g.InputFile, err)
+	}
+
+	data, err = decodeGFWList(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode file %s: %w", g.InputFile, err)
+	}
+
+	blocked := lib.NewEntry(g.Name)
+	exceptions := lib.NewEntry(g.Name)
+	seen := make(map[string]bool)
+	unsupported := 0
+
+	lineNum := 0
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lineNum++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '!' || line[0] == '[' {
+			// Skip empty lines, comments and headers
+			continue
+		}
+
+		target := blocked
+		column := 1
+		if rest, found := strings.CutPrefix(line, "@@"); found {
+			target, line, column = exceptions, rest, column+2
+		}
+
+		domain, checkErr := g.parseRule(token{value: line, column: column})
+		if domain == nil {
+			unsupported++
+			continue
+		}
+		if checkErr != nil {
+			checkErr.File, checkErr.Line = g.InputFile, lineNum
+		}
+		if keep, err := checkDomain(checkErr, g.DomainCheck); err != nil {
+			return nil, err
+		} else if !keep {
+			continue
+		}
+
+		// Full rules are written for both http and https, so only keep the first one
+		key := fmt.Sprintf("%t:%s:%s", target == exceptions, domain.GetType(), domain.GetValue())
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		target.AddDomain(domain)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", g.InputFile, err)
+	}
+
+	if unsupported > 0 {
+		slog.Warn("unsupported rules skipped", "file", g.InputFile, "count", unsupported)
+	}
+
+	switch g.Action {
+	case lib.ActionAdd:
+		if err := g.Naming.Add(container, blocked); err != nil {
+			return nil, err
+		}
+		// Exception rules unblock domains, so they are removed from the entry
+		if err := g.Naming.Remove(container, exceptions, lib.CaseRemovePrefix); err != nil {
+			return nil, err
+		}
+	case lib.ActionRemove:
+		if err := g.Naming.Remove(container, blocked, lib.CaseRemovePrefix); err != nil {
+			return nil, err
+		}
+	}
+
+	return container, nil
+}
+
+// parseRule parses an AutoProxy rule. It returns nil for rules that cannot be expressed as domain rules,
+// such as regular expressions, rules matching URL paths or with wildcards.
+func (g *GFWListIn) parseRule(tok token) (*router.Domain, *ParseError) {
+	rule := tok.value
+
+	// Regular expressions match whole URLs, e.g. /^https?:\/\/[^\/]+example\.com/, not host names
+	if strings.HasPrefix(rule, "/") {
+		return nil, nil
+	}
+
+	if strings.Contains(rule, "*") {
+		return nil, nil
+	}
+
+	switch {
+	case strings.HasPrefix(rule, "||"):
+		// Domain and its subdomains, e.g. ||example.com
+		host, ok := trimHost(rule[2:])
+		if !ok {
+			return nil, nil
+		}
+		return newCheckedDomain(router.Domain_RootDomain, token{value: host, column: tok.column + 2}, g.Attributes)
+	case strings.HasPrefix(rule, "|"):
+		// Beginning of URL, e.g. |http://example.com
+		rest := rule[1:]
+		scheme, host, found := strings.Cut(rest, "://")
+		if !found || (scheme != "http" && scheme != "https") {
+			return nil, nil
+		}
+		host, ok := trimHost(host)
+		if !ok {
+			return nil, nil
+		}
+		return newCheckedDomain(router.Domain_Full, token{value: host, column: tok.column + len(scheme) + 4}, g.Attributes)
+	case strings.HasPrefix(rule, "."):
+		// Subdomains, e.g. .example.com
+		host, ok := trimHost(rule[1:])
+		if !ok {
+			return nil, nil
+		}
+		return newCheckedDomain(router.Domain_RootDomain, token{value: host, column: tok.column + 1}, g.Attributes)
+	default:
+		// Keyword in URL, e.g. example
+		if strings.ContainsAny(rule, "/:|") {
+			return nil, nil
+		}
+		return &router.Domain{
+			Type:      router.Domain_Plain,
+			Value:     strings.ToLower(rule),
+			Attribute: slices.Clone(g.Attributes),
+		}, nil
+	}
+}
+
+// trimHost trims the trailing separator of a host and reports whether it is followed by a path
+func trimHost(host string) (string, bool) {
+	host = strings.TrimSuffix(strings.TrimSuffix(host, "^"), "/")
+	return host, host != "" && !strings.ContainsAny(host, "/:?^|")
+}
+
+// decodeGFWList decodes a base64 encoded GFWList. Lists which are not encoded are returned as is.
+func decodeGFWList(data []byte) ([]byte, error) {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) || bytes.HasPrefix(trimmed, []byte("!")) {
+		return data, nil
+	}
+
+	// Encoded lists are usually wrapped every 64 characters
+	encoded := strings.Join(strings.Fields(string(trimmed)), "")
+	return base64.StdEncoding.DecodeString(encoded)
+}
@@ -0,0 +1,176 @@
+package plaintext
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alexxyjiang/domain-list-custom/lib"
+	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
+)
+
+const (
+	TypeGFWListOut = "gfwlist"
+	DescGFWListOut = "Convert a domain list to GFWList (AutoProxy) format"
+)
+
+func init() {
+	lib.RegisterOutputConfigCreator(TypeGFWListOut, func(action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
+		return newGFWListOut(action, data)
+	})
+	lib.RegisterOutputConverter(TypeGFWListOut, &GFWListOut{
+		Type:        TypeGFWListOut,
+		Description: DescGFWListOut,
+	})
+}
+
+type GFWListOut struct {
+	Type         string
+	Action       lib.Action
+	Description  string
+	OutputDir    string
+	OutputName   string
+	List         string
+	Header       []string
+	Location     *time.Location
+	LastModified string
+	Base64       bool
+}
+
+type gfwListOutArgs struct {
+	List         string   `json:"list" required:"true" desc:"List to generate"`
+	OutputDir    string   `json:"outputDir" default:"./output" desc:"Output directory"`
+	OutputName   string   `json:"outputName" default:"gfwlist.txt" desc:"Output file name"`
+	Header       []string `json:"header" desc:"Comment lines written after the Last Modified line"`
+	TimeZone     string   `json:"timeZone" default:"UTC" desc:"Time zone of the Last Modified line"`
+	LastModified string   `json:"lastModified" desc:"Timestamp of the Last Modified line, Unix seconds or RFC 3339, $SOURCE_DATE_EPOCH or now if empty"`
+	Base64       *bool    `json:"base64" default:"true" desc:"Encode the file in base64 as the original GFWList does"`
+}
+
+func newGFWListOut(action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
+	var tmp gfwListOutArgs
+	if err := lib.UnmarshalArgs(data, &tmp); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(tmp.List) == "" {
+		return nil, fmt.Errorf("list is required")
+	}
+
+	loc, err := time.LoadLocation(tmp.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timeZone: %w", err)
+	}
+
+	if !lib.IsEmpty(tmp.LastModified) {
+		if _, err := lib.ParseTimestamp(tmp.LastModified); err != nil {
+			return nil, fmt.Errorf("invalid lastModified: %w", err)
+		}
+	}
+
+	return &GFWListOut{
+		Type:         TypeGFWListOut,
+		Action:       action,
+		Description:  DescGFWListOut,
+		OutputDir:    tmp.OutputDir,
+		OutputName:   tmp.OutputName,
+		List:         tmp.List,
+		Header:       tmp.Header,
+		Location:     loc,
+		LastModified: tmp.LastModified,
+		Base64:       *tmp.Base64,
+	}, nil
+}
+
+func (g *GFWListOut) GetType() string {
+	return g.Type
+}
+
+func (g *GFWListOut) GetAction() lib.Action {
+	return g.Action
+}
+
+func (g *GFWListOut) GetDescription() string {
+	return g.Description
+}
+
+func (g *GFWListOut) GetArgs() []lib.Arg {
+	return lib.DescribeArgs(gfwListOutArgs{})
+}
+
+func (g *GFWListOut) Output(container lib.Container) error {
+	// Create output directory
+	if err := os.MkdirAll(g.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	entry, found := container.GetEntry(g.List)
+	if !found {
+		return fmt.Errorf("entry %s not found for GFWList generation", strings.ToUpper(g.List))
+	}
+
+	lastModified, err := lib.BuildTime(g.LastModified)
+	if err != nil {
+		return err
+	}
+
+	data := g.marshal(entry, lastModified)
+	if g.Base64 {
+		data = []byte(base64.StdEncoding.EncodeToString(data))
+	}
+
+	filepath := filepath.Join(g.OutputDir, g.OutputName)
+	if err := os.WriteFile(filepath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", filepath, err)
+	}
+
+	slog.Info("✅ file generated", "filename", g.OutputName)
+	return nil
+}
+
+func (g *GFWListOut) marshal(entry *lib.Entry, lastModified time.Time) []byte {
+	var buf bytes.Buffer
+
+	loc := g.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	buf.WriteString("[AutoProxy 0.2.9]\n")
+	fmt.Fprintf(&buf, "! Last Modified: %s\n", lastModified.In(loc).Format(time.RFC1123))
+	for _, line := range g.Header {
+		buf.WriteString("! " + line + "\n")
+	}
+	buf.WriteString("\n")
+
+	for _, domain := range entry.GetDomains() {
+		ruleVal := strings.TrimSpace(domain.GetValue())
+		if len(ruleVal) == 0 {
+			continue
+		}
+
+		switch domain.Type {
+		case router.Domain_Full:
+			buf.WriteString("|http://" + ruleVal + "\n")
+			buf.WriteString("|https://" + ruleVal + "\n")
+		case router.Domain_RootDomain:
+			buf.WriteString("||" + ruleVal + "\n")
+		case router.Domain_Plain:
+			buf.WriteString(ruleVal + "\n")
+		case router.Domain_Regex:
+			if err := lib.ValidateRegex(ruleVal, lib.RegexTargetGFWList); err != nil {
+				slog.Warn("invalid regular expression dropped", "name", entry.GetName(), "regexp", ruleVal, "err", err)
+				continue
+			}
+			buf.WriteString("/" + ruleVal + "/\n")
+		}
+	}
+
+	return buf.Bytes()
+}
@@ -0,0 +1,252 @@
+package plaintext
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/alexxyjiang/domain-list-custom/lib"
+	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
+)
+
+const (
+	TypeHostsIn = "hosts"
+	DescHostsIn = "Convert hosts file format to other formats"
+)
+
+// localHostnames are the hostnames of the local machine commonly found in hosts files
+var localHostnames = map[string]bool{
+	"localhost":             true,
+	"localhost.localdomain": true,
+	"local":                 true,
+	"broadcasthost":         true,
+	"ip6-localhost":         true,
+	"ip6-loopback":          true,
+	"ip6-localnet":          true,
+	"ip6-mcastprefix":       true,
+	"ip6-allnodes":          true,
+	"ip6-allrouters":        true,
+	"ip6-allhosts":          true,
+	"0.0.0.0":               true,
+}
+
+func init() {
+	lib.RegisterInputConfigCreator(TypeHostsIn, func(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
+		return newHostsIn(action, data)
+	})
+	lib.RegisterInputConverter(TypeHostsIn, &HostsIn{
+		Type:        TypeHostsIn,
+		Description: DescHostsIn,
+	})
+}
+
+type HostsIn struct {
+	Type        string
+	Action      lib.Action
+	Description string
+	Name        string
+	InputFile   string
+	RuleType    router.Domain_Type
+	Attributes  []*router.Domain_Attribute
+	DomainCheck lib.CheckMode
+	Naming      *lib.EntryNaming
+}
+
+type hostsInArgs struct {
+	Name        string   `json:"name" required:"true" desc:"Name of the list to load the rules into"`
+	InputFile   string   `json:"inputFile" required:"true" desc:"Path of the hosts file"`
+	RuleType    string   `json:"ruleType" default:"full" desc:"Type of the rules: full or domain"`
+	Attributes  []string `json:"attributes" desc:"Attributes attached to every rule, e.g. @ads"`
+	DomainCheck string   `json:"domainCheck" default:"skip" desc:"Handling of invalid domains and lines without an IP address: error, warn or skip"`
+	lib.NamingArgs
+}
+
+func newHostsIn(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
+	var tmp hostsInArgs
+	if err := lib.UnmarshalArgs(data, &tmp); err != nil {
+		return nil, err
+	}
+
+	if action != lib.ActionAdd && action != lib.ActionRemove {
+		return nil, fmt.Errorf("unsupported action: %s", action)
+	}
+
+	if strings.TrimSpace(tmp.Name) == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	var ruleType router.Domain_Type
+	switch strings.ToLower(strings.TrimSpace(tmp.RuleType)) {
+	case "full":
+		ruleType = router.Domain_Full
+	case "domain":
+		ruleType = router.Domain_RootDomain
+	default:
+		return nil, fmt.Errorf("unsupported ruleType: %s", tmp.RuleType)
+	}
+
+	attrs, err := parseAttributeArgs(tmp.Attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	domainCheck, err := lib.ParseCheckMode(tmp.DomainCheck, lib.CheckModeSkip)
+	if err != nil {
+		return nil, fmt.Errorf("invalid domainCheck: %w", err)
+	}
+
+	naming, err := tmp.NamingArgs.EntryNaming()
+	if err != nil {
+		return nil, err
+	}
+
+	return &HostsIn{
+		Type:        TypeHostsIn,
+		Action:      action,
+		Description: DescHostsIn,
+		Name:        tmp.Name,
+		InputFile:   tmp.InputFile,
+		RuleType:    ruleType,
+		Attributes:  attrs,
+		DomainCheck: domainCheck,
+		Naming:      naming,
+	}, nil
+}
+
+func (h *HostsIn) GetType() string {
+	return h.Type
+}
+
+func (h *HostsIn) GetAction() lib.Action {
+	return h.Action
+}
+
+func (h *HostsIn) GetDescription() string {
+	return h.Description
+}
+
+func (h *HostsIn) GetArgs() []lib.Arg {
+	return lib.DescribeArgs(hostsInArgs{})
+}
+
+func (h *HostsIn) Input(container lib.Container) (lib.Container, error) {
+	file, err := os.Open(h.InputFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", h.InputFile, err)
+	}
+	defer file.Close()
+
+	entry := lib.NewEntry(h.Name)
+
+	lineNum := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineNum++
+
+		tokens := tokenize(scanner.Text())
+		if len(tokens) < 2 {
+			continue
+		}
+
+		// Lines start with an IP address followed by hostnames
+		if _, err := netip.ParseAddr(tokens[0].value); err != nil {
+			parseErr := newParseError(tokens[0].column, "invalid IP address %q", tokens[0].value)
+			parseErr.File, parseErr.Line = h.InputFile, lineNum
+			// Such lines have no rule to keep, so the warn mode skips them as well
+			if h.DomainCheck == lib.CheckModeError {
+				return nil, parseErr
+			}
+			slog.Warn("skipping invalid line", "err", parseErr)
+			continue
+		}
+
+		for _, tok := range tokens[1:] {
+			if localHostnames[strings.ToLower(tok.value)] {
+				continue
+			}
+
+			domain, checkErr := newCheckedDomain(h.RuleType, tok, h.Attributes)
+			if checkErr != nil {
+				checkErr.File, checkErr.Line = h.InputFile, lineNum
+			}
+			if keep, err := checkDomain(checkErr, h.DomainCheck); err != nil {
+				return nil, err
+			} else if keep {
+				entry.AddDomain(domain)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", h.InputFile, err)
+	}
+
+	switch h.Action {
+	case lib.ActionAdd:
+		if err := h.Naming.Add(container, entry); err != nil {
+			return nil, err
+		}
+	case lib.ActionRemove:
+		if err := h.Naming.Remove(container, entry, lib.CaseRemovePrefix); err != nil {
+			return nil, err
+		}
+	}
+
+	return container, nil
+}
+
+// parseAttributeArgs parses attributes from config arguments in the form of @key or @key=value
+func parseAttributeArgs(attrStrings []string) ([]*router.Domain_Attribute, error) {
+	attrs := make([]*router.Domain_Attribute, 0, len(attrStrings))
+	for _, attrString := range attrStrings {
+		key, value, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(attrString), "@"), "=")
+		attr, err := lib.ParseAttributeValue(key, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid attribute %q: %w", attrString, err)
+		}
+		attrs = append(attrs, attr)
+	}
+	return attrs, nil
+}
+
+// newCheckedDomain creates a rule of the type with the normalized domain of the token and a copy of
+// the attributes. If the domain is invalid, the rule keeps the lowercased domain and the validation
+// error is returned.
+func newCheckedDomain(ruleType router.Domain_Type, tok token, attrs []*router.Domain_Attribute) (*router.Domain, *ParseError) {
+	domain := &router.Domain{
+		Type:      ruleType,
+		Value:     strings.ToLower(tok.value),
+		Attribute: slices.Clone(attrs),
+	}
+
+	value, err := lib.NormalizeDomain(tok.value)
+	if err != nil {
+		return domain, wrapParseError(tok.column, err)
+	}
+	domain.Value = value
+
+	return domain, nil
+}
+
+// checkDomain reports whether a rule with the validation error is kept according to the check mode
+func checkDomain(checkErr *ParseError, mode lib.CheckMode) (bool, error) {
+	if checkErr == nil {
+		return true, nil
+	}
+
+	switch mode {
+	case lib.CheckModeError:
+		return false, checkErr
+	case lib.CheckModeWarn:
+		slog.Warn("invalid rule", "err", checkErr)
+		return true, nil
+	default:
+		slog.Warn("skipping invalid rule", "err", checkErr)
+		return false, nil
+	}
+}
@@ -0,0 +1,153 @@
+package plaintext
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/alexxyjiang/domain-list-custom/lib"
+	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
+)
+
+// ParseError is an error in a rule line, located by file name, line and column.
+// Err holds the underlying diagnostic, such as a *lib.DomainError, if any.
+type ParseError struct {
+	File   string
+	Line   int
+	Column int
+	Msg    string
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Msg)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+func newParseError(column int, format string, args ...any) *ParseError {
+	return &ParseError{
+		Column: column,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+func wrapParseError(column int, err error) *ParseError {
+	return &ParseError{
+		Column: column,
+		Msg:    err.Error(),
+		Err:    err,
+	}
+}
+
+// token is a whitespace separated part of a rule line with its 1-based column
+type token struct {
+	value  string
+	column int
+}
+
+// tokenize splits a rule line into tokens, ignoring everything after a comment sign '#'
+func tokenize(line string) []token {
+	tokens := make([]token, 0, 4)
+
+	start := -1
+	column, startColumn := 0, 0
+	for idx, r := range line {
+		column++
+		if r == '#' || unicode.IsSpace(r) {
+			if start != -1 {
+				tokens = append(tokens, token{value: line[start:idx], column: startColumn})
+				start = -1
+			}
+			if r == '#' {
+				return tokens
+			}
+			continue
+		}
+		if start == -1 {
+			start, startColumn = idx, column
+		}
+	}
+
+	if start != -1 {
+		tokens = append(tokens, token{value: line[start:], column: startColumn})
+	}
+	return tokens
+}
+
+// textFrom returns the text of a rule line from the 1-based column up to the comment sign '#'
+func textFrom(line string, column int) string {
+	for idx := range line {
+		if column--; column == 0 {
+			line = line[idx:]
+			break
+		}
+	}
+	text, _, _ := strings.Cut(line, "#")
+	return text
+}
+
+// parseTypeRule parses a rule in the form of [type:]value. Only the first ':' separates
+// the type from the value, so values like regular expressions may contain ':'.
+func parseTypeRule(tok token) (*router.Domain, *ParseError) {
+	rule := new(router.Domain)
+
+	ruleType, ruleVal, hasType := strings.Cut(tok.value, ":")
+	if !hasType {
+		// Rule without type prefix
+		rule.Type = router.Domain_RootDomain
+		rule.Value = strings.ToLower(ruleType)
+		return rule, nil
+	}
+
+	if ruleVal == "" {
+		return nil, newParseError(valueColumn(tok), "empty value for rule type %q", ruleType)
+	}
+	rule.Value = strings.ToLower(ruleVal)
+
+	switch strings.ToLower(ruleType) {
+	case "full":
+		rule.Type = router.Domain_Full
+	case "domain":
+		rule.Type = router.Domain_RootDomain
+	case "keyword":
+		rule.Type = router.Domain_Plain
+	case "regexp":
+		rule.Type = router.Domain_Regex
+		rule.Value = ruleVal // Keep original case for regex
+	default:
+		return nil, newParseError(tok.column, "unknown rule type %q", ruleType)
+	}
+
+	return rule, nil
+}
+
+// valueColumn returns the column of the value of a rule token in the form of [type:]value
+func valueColumn(tok token) int {
+	ruleType, _, hasType := strings.Cut(tok.value, ":")
+	if !hasType {
+		return tok.column
+	}
+	return tok.column + utf8.RuneCountInString(ruleType) + 1
+}
+
+// parseAttribute parses an attribute in the form of @key or @key=value with an integer value
+func parseAttribute(tok token) (*router.Domain_Attribute, *ParseError) {
+	if !strings.HasPrefix(tok.value, "@") {
+		return nil, newParseError(tok.column, "unexpected token %q, attributes must start with '@'", tok.value)
+	}
+
+	key, value, hasValue := strings.Cut(tok.value[1:], "=") // Trim attribute prefix '@'
+	if hasValue && value == "" {
+		return nil, newParseError(tok.column, "empty value of attribute %q", tok.value)
+	}
+
+	attr, err := lib.ParseAttributeValue(key, value)
+	if err != nil {
+		return nil, wrapParseError(tok.column, err)
+	}
+	return attr, nil
+}
@@ -0,0 +1,182 @@
+package plaintext
+
+import (
+	"reflect"
+	"testing"
+
+	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []token
+	}{
+		{name: "empty", line: "", want: []token{}},
+		{name: "only comment", line: "# comment", want: []token{}},
+		{name: "only spaces", line: " \t ", want: []token{}},
+		{
+			name: "rule with attributes",
+			line: "domain:example.com @ads @cn",
+			want: []token{{"domain:example.com", 1}, {"@ads", 20}, {"@cn", 25}},
+		},
+		{
+			name: "leading whitespace",
+			line: "\t  full:example.com",
+			want: []token{{"full:example.com", 4}},
+		},
+		{
+			name: "inline comment after space",
+			line: "example.com # comment @ads",
+			want: []token{{"example.com", 1}},
+		},
+		{
+			name: "inline comment without space",
+			line: "example.com@ads#comment",
+			want: []token{{"example.com@ads", 1}},
+		},
+		{
+			name: "comment inside regexp",
+			line: `regexp:^a#b$ @ads`,
+			want: []token{{"regexp:^a", 1}},
+		},
+		{
+			name: "columns count runes",
+			line: "domain:例子.测试 @cn",
+			want: []token{{"domain:例子.测试", 1}, {"@cn", 14}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tokenize(tt.line); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenize(%q) = %v, want %v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextFrom(t *testing.T) {
+	tests := []struct {
+		line   string
+		column int
+		want   string
+	}{
+		{line: "include:google @cn && !@ads", column: 16, want: "@cn && !@ads"},
+		{line: "include:google (@cn) # comment", column: 16, want: "(@cn) "},
+		{line: "include:例子 @cn", column: 12, want: "@cn"},
+	}
+
+	for _, tt := range tests {
+		if got := textFrom(tt.line, tt.column); got != tt.want {
+			t.Errorf("textFrom(%q, %d) = %q, want %q", tt.line, tt.column, got, tt.want)
+		}
+	}
+}
+
+func TestParseTypeRule(t *testing.T) {
+	tests := []struct {
+		name      string
+		tok       token
+		wantType  router.Domain_Type
+		wantValue string
+		errColumn int
+	}{
+		{name: "no type", tok: token{"Example.COM", 1}, wantType: router.Domain_RootDomain, wantValue: "example.com"},
+		{name: "full", tok: token{"full:Example.com", 1}, wantType: router.Domain_Full, wantValue: "example.com"},
+		{name: "domain", tok: token{"domain:example.com", 1}, wantType: router.Domain_RootDomain, wantValue: "example.com"},
+		{name: "keyword", tok: token{"keyword:Google", 1}, wantType: router.Domain_Plain, wantValue: "google"},
+		{name: "type is case insensitive", tok: token{"FULL:example.com", 1}, wantType: router.Domain_Full, wantValue: "example.com"},
+		{name: "regexp keeps case", tok: token{`regexp:^A\.com$`, 1}, wantType: router.Domain_Regex, wantValue: `^A\.com$`},
+		{name: "colon inside regexp", tok: token{`regexp:^(?:www\.)?a:b$`, 1}, wantType: router.Domain_Regex, wantValue: `^(?:www\.)?a:b$`},
+		{name: "empty value", tok: token{"full:", 5}, errColumn: 10},
+		{name: "empty regexp", tok: token{"regexp:", 1}, errColumn: 8},
+		{name: "unknown type", tok: token{"suffix:example.com", 3}, errColumn: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTypeRule(tt.tok)
+			if tt.errColumn != 0 {
+				if err == nil {
+					t.Fatalf("parseTypeRule(%q) = %v, want error", tt.tok.value, got)
+				}
+				if err.Column != tt.errColumn {
+					t.Errorf("parseTypeRule(%q) error column = %d, want %d", tt.tok.value, err.Column, tt.errColumn)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTypeRule(%q) error = %v", tt.tok.value, err)
+			}
+			if got.GetType() != tt.wantType || got.GetValue() != tt.wantValue {
+				t.Errorf("parseTypeRule(%q) = %v:%q, want %v:%q", tt.tok.value, got.GetType(), got.GetValue(), tt.wantType, tt.wantValue)
+			}
+		})
+	}
+}
+
+func TestValueColumn(t *testing.T) {
+	tests := []struct {
+		tok  token
+		want int
+	}{
+		{tok: token{"example.com", 3}, want: 3},
+		{tok: token{"full:example.com", 3}, want: 8},
+		{tok: token{`regexp:a:b`, 1}, want: 8},
+	}
+
+	for _, tt := range tests {
+		if got := valueColumn(tt.tok); got != tt.want {
+			t.Errorf("valueColumn(%q) = %d, want %d", tt.tok.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseAttribute(t *testing.T) {
+	tests := []struct {
+		name      string
+		tok       token
+		wantKey   string
+		wantInt   int64
+		isInt     bool
+		errColumn int
+	}{
+		{name: "boolean", tok: token{"@ADS", 1}, wantKey: "ads"},
+		{name: "negated key", tok: token{"@!cn", 1}, wantKey: "!cn"},
+		{name: "integer", tok: token{"@priority=10", 1}, wantKey: "priority", wantInt: 10, isInt: true},
+		{name: "negative integer", tok: token{"@priority=-1", 1}, wantKey: "priority", wantInt: -1, isInt: true},
+		{name: "missing prefix", tok: token{"ads", 7}, errColumn: 7},
+		{name: "empty key", tok: token{"@", 4}, errColumn: 4},
+		{name: "empty value", tok: token{"@priority=", 4}, errColumn: 4},
+		{name: "non integer value", tok: token{"@priority=high", 4}, errColumn: 4},
+		{name: "unusual key", tok: token{"@A.b-c_d", 1}, wantKey: "a.b-c_d"},
+		{name: "invalid key", tok: token{"@x+y", 4}, errColumn: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseAttribute(tt.tok)
+			if tt.errColumn != 0 {
+				if err == nil {
+					t.Fatalf("parseAttribute(%q) = %v, want error", tt.tok.value, got)
+				}
+				if err.Column != tt.errColumn {
+					t.Errorf("parseAttribute(%q) error column = %d, want %d", tt.tok.value, err.Column, tt.errColumn)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseAttribute(%q) error = %v", tt.tok.value, err)
+			}
+			if got.GetKey() != tt.wantKey {
+				t.Errorf("parseAttribute(%q) key = %q, want %q", tt.tok.value, got.GetKey(), tt.wantKey)
+			}
+			value, isInt := got.GetTypedValue().(*router.Domain_Attribute_IntValue)
+			if isInt != tt.isInt || (isInt && value.IntValue != tt.wantInt) {
+				t.Errorf("parseAttribute(%q) = %v, want integer %v %d", tt.tok.value, got.GetTypedValue(), tt.isInt, tt.wantInt)
+			}
+		})
+	}
+}
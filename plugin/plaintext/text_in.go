@@ -0,0 +1,214 @@
+package plaintext
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alexxyjiang/domain-list-custom/lib"
+	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
+)
+
+const (
+	TypeTextIn = "text"
+	DescTextIn = "Convert plaintext format generated by the text output to other formats"
+)
+
+func init() {
+	lib.RegisterInputConfigCreator(TypeTextIn, func(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
+		return newTextIn(action, data)
+	})
+	lib.RegisterInputConverter(TypeTextIn, &TextIn{
+		Type:        TypeTextIn,
+		Description: DescTextIn,
+	})
+}
+
+type TextIn struct {
+	Type        string
+	Action      lib.Action
+	Description string
+	InputDir    string
+	InputExt    string
+	Want        map[string]bool
+	RemoveCase  lib.CaseRemove
+	Naming      *lib.EntryNaming
+}
+
+type textInArgs struct {
+	InputDir    string   `json:"inputDir" required:"true" desc:"Directory of the .txt files generated by the text output"`
+	Want        []string `json:"wantedList" desc:"Lists to load, all lists if empty"`
+	RemoveEntry bool     `json:"removeEntry" desc:"Remove whole lists instead of their rules with the remove action"`
+	lib.NamingArgs
+}
+
+func newTextIn(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
+	var tmp textInArgs
+	if err := lib.UnmarshalArgs(data, &tmp); err != nil {
+		return nil, err
+	}
+
+	if action != lib.ActionAdd && action != lib.ActionRemove {
+		return nil, fmt.Errorf("unsupported action: %s", action)
+	}
+
+	removeCase := lib.CaseRemovePrefix
+	if tmp.RemoveEntry {
+		removeCase = lib.CaseRemoveEntry
+	}
+
+	// Filter wanted list
+	wantList := make(map[string]bool)
+	for _, want := range tmp.Want {
+		if want = strings.ToUpper(strings.TrimSpace(want)); want != "" {
+			wantList[want] = true
+		}
+	}
+
+	naming, err := tmp.NamingArgs.EntryNaming()
+	if err != nil {
+		return nil, err
+	}
+
+	return &TextIn{
+		Type:        TypeTextIn,
+		Action:      action,
+		Description: DescTextIn,
+		InputDir:    tmp.InputDir,
+		InputExt:    ".txt",
+		Want:        wantList,
+		RemoveCase:  removeCase,
+		Naming:      naming,
+	}, nil
+}
+
+func (t *TextIn) GetType() string {
+	return t.Type
+}
+
+func (t *TextIn) GetAction() lib.Action {
+	return t.Action
+}
+
+func (t *TextIn) GetDescription() string {
+	return t.Description
+}
+
+func (t *TextIn) GetArgs() []lib.Arg {
+	return lib.DescribeArgs(textInArgs{})
+}
+
+func (t *TextIn) Input(container lib.Container) (lib.Container, error) {
+	return container, filepath.Walk(t.InputDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.EqualFold(filepath.Ext(path), t.InputExt) {
+			return nil
+		}
+
+		name := strings.ToUpper(strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+
+		// Filter by wanted list if specified
+		if len(t.Want) > 0 && !t.Want[name] {
+			return nil
+		}
+
+		entry, err := t.processFile(path, name)
+		if err != nil {
+			return err
+		}
+
+		switch t.Action {
+		case lib.ActionAdd:
+			return t.Naming.Add(container, entry)
+		case lib.ActionRemove:
+			return t.Naming.Remove(container, entry, t.RemoveCase)
+		}
+		return nil
+	})
+}
+
+func (t *TextIn) processFile(path string, name string) (*lib.Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", path, err)
+	}
+	defer file.Close()
+
+	entry := lib.NewEntry(name)
+
+	lineNum := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineNum++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		domain, parseErr := parseTextRule(line)
+		if parseErr != nil {
+			parseErr.File = path
+			parseErr.Line = lineNum
+			return nil, parseErr
+		}
+		entry.AddDomain(domain)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+
+	return entry, nil
+}
+
+// parseTextRule parses a rule in the form of type:value[:@attr1,@attr2] generated by Entry.MarshalText
+func parseTextRule(line string) (*router.Domain, *ParseError) {
+	rule, attrs := line, ""
+	if idx := strings.LastIndex(line, ":@"); idx != -1 && isAttributeList(line[idx+1:]) {
+		rule, attrs = line[:idx], line[idx+1:]
+	}
+
+	domain, err := parseTypeRule(token{value: rule, column: 1})
+	if err != nil {
+		return nil, err
+	}
+
+	if attrs == "" {
+		return domain, nil
+	}
+
+	column := utf8.RuneCountInString(rule) + 2
+	for _, attrString := range strings.Split(attrs, ",") {
+		attr, err := parseAttribute(token{value: attrString, column: column})
+		if err != nil {
+			return nil, err
+		}
+		domain.Attribute = append(domain.Attribute, attr)
+		column += utf8.RuneCountInString(attrString) + 1
+	}
+
+	return domain, nil
+}
+
+// isAttributeList checks if s is a list of attributes in the form of @attr1,@attr2=value written by
+// lib.FormatAttribute, to tell them apart from values containing ":@", such as some regular expressions
+func isAttributeList(s string) bool {
+	for _, attr := range strings.Split(s, ",") {
+		key, value, hasValue := strings.Cut(strings.TrimPrefix(attr, "@"), "=")
+		if !strings.HasPrefix(attr, "@") || !lib.IsAttributeKey(strings.ToLower(key)) {
+			return false
+		}
+		if _, err := strconv.ParseInt(value, 10, 64); hasValue && err != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -6,7 +6,6 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
-	"slices"
 	"strings"
 
 	"github.com/alexxyjiang/domain-list-custom/lib"
@@ -22,45 +21,44 @@ func init() {
 		return newTextOut(action, data)
 	})
 	lib.RegisterOutputConverter(TypeTextOut, &TextOut{
+		Type:        TypeTextOut,
 		Description: DescTextOut,
 	})
 }
 
 type TextOut struct {
-	Type        string
-	Action      lib.Action
-	Description string
-	OutputDir   string
-	OutputExt   string
-	Want        []string
-	Exclude     []string
+	Type           string
+	Action         lib.Action
+	Description    string
+	OutputDir      string
+	OutputExt      string
+	Want           []string
+	Exclude        []string
+	AttributeLists bool
 }
 
-func newTextOut(action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
-	var tmp struct {
-		OutputDir string   `json:"outputDir"`
-		Want      []string `json:"wantedList"`
-		Exclude   []string `json:"excludedList"`
-	}
-
-	if len(data) > 0 {
-		if err := json.Unmarshal(data, &tmp); err != nil {
-			return nil, err
-		}
-	}
+type textOutArgs struct {
+	OutputDir      string   `json:"outputDir" default:"./output" desc:"Output directory"`
+	Want           []string `json:"wantedList" desc:"Lists to export, all lists if empty"`
+	Exclude        []string `json:"excludedList" desc:"Lists to exclude"`
+	AttributeLists bool     `json:"attributeLists" desc:"Also generate a NAME@ATTR list for every attribute of a list"`
+}
 
-	if tmp.OutputDir == "" {
-		tmp.OutputDir = "./output"
+func newTextOut(action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
+	var tmp textOutArgs
+	if err := lib.UnmarshalArgs(data, &tmp); err != nil {
+		return nil, err
 	}
 
 	return &TextOut{
-		Type:        TypeTextOut,
-		Action:      action,
-		Description: DescTextOut,
-		OutputDir:   tmp.OutputDir,
-		OutputExt:   ".txt",
-		Want:        tmp.Want,
-		Exclude:     tmp.Exclude,
+		Type:           TypeTextOut,
+		Action:         action,
+		Description:    DescTextOut,
+		OutputDir:      tmp.OutputDir,
+		OutputExt:      ".txt",
+		Want:           tmp.Want,
+		Exclude:        tmp.Exclude,
+		AttributeLists: tmp.AttributeLists,
 	}, nil
 }
 
@@ -76,66 +74,52 @@ func (t *TextOut) GetDescription() string {
 	return t.Description
 }
 
+func (t *TextOut) GetArgs() []lib.Arg {
+	return lib.DescribeArgs(textOutArgs{})
+}
+
 func (t *TextOut) Output(container lib.Container) error {
 	// Create output directory
 	if err := os.MkdirAll(t.OutputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	for _, name := range t.filterAndSortList(container) {
+	for _, name := range lib.FilterAndSortList(container, t.Want, t.Exclude) {
 		entry, found := container.GetEntry(name)
 		if !found {
 			slog.Debug("❌️ entry not found", "name", name)
 			continue
 		}
 
-		data, err := entry.MarshalText()
-		if err != nil {
-			return fmt.Errorf("failed to marshal entry %s: %w", name, err)
+		if err := t.writeEntry(entry); err != nil {
+			return err
 		}
 
-		filename := strings.ToLower(entry.GetName()) + t.OutputExt
-		filepath := filepath.Join(t.OutputDir, filename)
-
-		if err := os.WriteFile(filepath, data, 0644); err != nil {
-			return fmt.Errorf("failed to write file %s: %w", filepath, err)
+		if t.AttributeLists {
+			for _, attrEntry := range lib.NewAttributeEntries(container, name) {
+				if err := t.writeEntry(attrEntry); err != nil {
+					return err
+				}
+			}
 		}
-
-		slog.Info("✅ file generated", "filename", filename)
 	}
 
 	return nil
 }
 
-func (t *TextOut) filterAndSortList(container lib.Container) []string {
-	excludeMap := make(map[string]bool)
-	for _, exclude := range t.Exclude {
-		if exclude = strings.ToUpper(strings.TrimSpace(exclude)); exclude != "" {
-			excludeMap[exclude] = true
-		}
+func (t *TextOut) writeEntry(entry *lib.Entry) error {
+	data, err := entry.MarshalText()
+	if err != nil {
+		return fmt.Errorf("failed to marshal entry %s: %w", entry.GetName(), err)
 	}
 
-	wantList := make([]string, 0, len(t.Want))
-	for _, want := range t.Want {
-		if want = strings.ToUpper(strings.TrimSpace(want)); want != "" && !excludeMap[want] {
-			wantList = append(wantList, want)
-		}
-	}
+	filename := strings.ToLower(entry.GetName()) + t.OutputExt
+	filepath := filepath.Join(t.OutputDir, filename)
 
-	if len(wantList) > 0 {
-		slices.Sort(wantList)
-		return wantList
+	if err := os.WriteFile(filepath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", filepath, err)
 	}
 
-	list := make([]string, 0, 300)
-	for entry := range container.Loop() {
-		name := entry.GetName()
-		if excludeMap[name] {
-			continue
-		}
-		list = append(list, name)
-	}
-
-	slices.Sort(list)
-	return list
+	slog.Info("✅ file generated", "filename", filename)
+	return nil
 }
@@ -0,0 +1,108 @@
+package plaintext
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/alexxyjiang/domain-list-custom/lib"
+	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
+)
+
+func TestTextRoundTrip(t *testing.T) {
+	boolAttr := func(key string) *router.Domain_Attribute {
+		return &router.Domain_Attribute{Key: key, TypedValue: &router.Domain_Attribute_BoolValue{BoolValue: true}}
+	}
+	intAttr := func(key string, value int64) *router.Domain_Attribute {
+		return &router.Domain_Attribute{Key: key, TypedValue: &router.Domain_Attribute_IntValue{IntValue: value}}
+	}
+
+	entry := lib.NewEntry("mixed")
+	for _, domain := range []*router.Domain{
+		{Type: router.Domain_RootDomain, Value: "example.com"},
+		{Type: router.Domain_RootDomain, Value: "ads.example.com", Attribute: []*router.Domain_Attribute{boolAttr("ads"), boolAttr("!cn")}},
+		{Type: router.Domain_Full, Value: "www.example.com", Attribute: []*router.Domain_Attribute{intAttr("priority", 10), intAttr("weight", -3)}},
+		{Type: router.Domain_RootDomain, Value: "unusual.example.com", Attribute: []*router.Domain_Attribute{boolAttr("a.b"), boolAttr("x-y_z"), boolAttr("0")}},
+		{Type: router.Domain_Plain, Value: "tracker", Attribute: []*router.Domain_Attribute{boolAttr("ads")}},
+		{Type: router.Domain_Regex, Value: `^ads\d+:@example\.com$`},
+		{Type: router.Domain_Regex, Value: `^a:@b$`, Attribute: []*router.Domain_Attribute{intAttr("v1.2", 0)}},
+	} {
+		entry.AddDomain(domain)
+	}
+
+	source := lib.NewSimpleContainer()
+	if err := source.Add(entry); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	dir := t.TempDir()
+	args, _ := json.Marshal(map[string]string{"outputDir": dir, "inputDir": dir})
+
+	out, err := newTextOut(lib.ActionOutput, args)
+	if err != nil {
+		t.Fatalf("newTextOut() error = %v", err)
+	}
+	if err := out.Output(source); err != nil {
+		t.Fatalf("Output() error = %v", err)
+	}
+
+	in, err := newTextIn(lib.ActionAdd, args)
+	if err != nil {
+		t.Fatalf("newTextIn() error = %v", err)
+	}
+	loaded, err := in.Input(lib.NewSimpleContainer())
+	if err != nil {
+		t.Fatalf("Input() error = %v", err)
+	}
+
+	got, found := loaded.GetEntry("mixed")
+	if !found {
+		t.Fatalf("entry MIXED not loaded, got %v", loaded.GetNames())
+	}
+
+	format := func(domains []*router.Domain) []string {
+		rules := make([]string, 0, len(domains))
+		for _, domain := range domains {
+			rules = append(rules, lib.FormatDomain(domain))
+		}
+		return rules
+	}
+	if got, want := format(got.GetDomains()), format(entry.GetDomains()); !slices.Equal(got, want) {
+		t.Errorf("round trip = %q, want %q", got, want)
+	}
+
+	// Integer values must stay integers rather than becoming part of the key
+	for _, domain := range got.GetDomains() {
+		for _, attr := range domain.GetAttribute() {
+			if attr.GetKey() == "priority" && attr.GetIntValue() != 10 {
+				t.Errorf("priority = %v, want integer 10", attr.GetTypedValue())
+			}
+		}
+	}
+}
+
+func TestIsAttributeList(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{s: "@ads", want: true},
+		{s: "@ads,@!cn", want: true},
+		{s: "@a.b,@x-y_z", want: true},
+		{s: "@priority=10,@weight=-3", want: true},
+		{s: "@ADS", want: true},
+		{s: "@", want: false},
+		{s: "@!", want: false},
+		{s: "ads", want: false},
+		{s: "@ads,", want: false},
+		{s: "@x+y", want: false},
+		{s: "@priority=high", want: false},
+		{s: `@example\.com$`, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := isAttributeList(tt.s); got != tt.want {
+			t.Errorf("isAttributeList(%q) = %t, want %t", tt.s, got, tt.want)
+		}
+	}
+}
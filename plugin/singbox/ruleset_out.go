@@ -0,0 +1,222 @@
+package singbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/alexxyjiang/domain-list-custom/lib"
+	"github.com/sagernet/sing-box/common/srs"
+	C "github.com/sagernet/sing-box/constant"
+	"github.com/sagernet/sing-box/option"
+	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
+)
+
+const (
+	TypeRuleSetOut = "singboxRuleSet"
+	DescRuleSetOut = "Convert domain lists to sing-box rule-set format"
+)
+
+const (
+	FormatSource = "json"
+	FormatBinary = "srs"
+)
+
+func init() {
+	lib.RegisterOutputConfigCreator(TypeRuleSetOut, func(action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
+		return newRuleSetOut(action, data)
+	})
+	lib.RegisterOutputConverter(TypeRuleSetOut, &RuleSetOut{
+		Type:        TypeRuleSetOut,
+		Description: DescRuleSetOut,
+	})
+}
+
+type RuleSetOut struct {
+	Type         string
+	Action       lib.Action
+	Description  string
+	OutputDir    string
+	Version      uint8
+	Formats      []string
+	Want         []string
+	Exclude      []string
+	ExcludeAttrs lib.ExcludeAttrs
+}
+
+// ruleSetSource is the JSON source format of a rule-set
+type ruleSetSource struct {
+	Version uint8                        `json:"version"`
+	Rules   []option.DefaultHeadlessRule `json:"rules"`
+}
+
+type ruleSetOutArgs struct {
+	OutputDir    string   `json:"outputDir" default:"./output" desc:"Output directory"`
+	Version      uint8    `json:"version" default:"2" desc:"Rule-set version: 1, 2 or 3"`
+	Formats      []string `json:"formats" default:"json,srs" desc:"Formats to generate: json and/or srs"`
+	Want         []string `json:"wantedList" desc:"Lists to export, all lists if empty"`
+	Exclude      []string `json:"excludedList" desc:"Lists to exclude"`
+	ExcludeAttrs string   `json:"excludeAttrs" desc:"Attributes of the rules to exclude from lists, e.g. cn@!cn@ads,geolocation-cn@!cn"`
+}
+
+func newRuleSetOut(action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
+	var tmp ruleSetOutArgs
+	if err := lib.UnmarshalArgs(data, &tmp); err != nil {
+		return nil, err
+	}
+
+	switch tmp.Version {
+	case C.RuleSetVersion1, C.RuleSetVersion2, C.RuleSetVersion3:
+	default:
+		return nil, fmt.Errorf("unsupported rule-set version: %d", tmp.Version)
+	}
+
+	formats := make([]string, 0, 2)
+	for _, format := range tmp.Formats {
+		format = strings.ToLower(strings.TrimSpace(format))
+		if format != FormatSource && format != FormatBinary {
+			return nil, fmt.Errorf("unknown format: %s", format)
+		}
+		formats = append(formats, format)
+	}
+
+	// Process exclude attributes
+	excludeAttrs, err := lib.ParseExcludeAttrs(tmp.ExcludeAttrs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid excludeAttrs: %w", err)
+	}
+
+	return &RuleSetOut{
+		Type:         TypeRuleSetOut,
+		Action:       action,
+		Description:  DescRuleSetOut,
+		OutputDir:    tmp.OutputDir,
+		Version:      tmp.Version,
+		Formats:      slices.Compact(formats),
+		Want:         tmp.Want,
+		Exclude:      tmp.Exclude,
+		ExcludeAttrs: excludeAttrs,
+	}, nil
+}
+
+func (r *RuleSetOut) GetType() string {
+	return r.Type
+}
+
+func (r *RuleSetOut) GetAction() lib.Action {
+	return r.Action
+}
+
+func (r *RuleSetOut) GetDescription() string {
+	return r.Description
+}
+
+func (r *RuleSetOut) GetArgs() []lib.Arg {
+	return lib.DescribeArgs(ruleSetOutArgs{})
+}
+
+func (r *RuleSetOut) Output(container lib.Container) error {
+	// Create output directory
+	if err := os.MkdirAll(r.OutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	for _, name := range lib.FilterAndSortList(container, r.Want, r.Exclude) {
+		entry, found := container.GetEntry(name)
+		if !found {
+			slog.Debug("❌️ entry not found", "name", name)
+			continue
+		}
+
+		rules := make([]option.DefaultHeadlessRule, 0, 1)
+		if rule := r.toRule(entry); rule != nil {
+			rules = append(rules, *rule)
+		}
+
+		for _, format := range r.Formats {
+			data, err := r.marshal(rules, format)
+			if err != nil {
+				return fmt.Errorf("failed to marshal entry %s: %w", name, err)
+			}
+
+			filename := strings.ToLower(entry.GetName()) + "." + format
+			filepath := filepath.Join(r.OutputDir, filename)
+
+			if err := os.WriteFile(filepath, data, 0644); err != nil {
+				return fmt.Errorf("failed to write file %s: %w", filepath, err)
+			}
+
+			slog.Info("✅ file generated", "filename", filename)
+		}
+	}
+
+	return nil
+}
+
+// toRule converts the domains of an entry to a headless rule, returning nil if there is no domain
+func (r *RuleSetOut) toRule(entry *lib.Entry) *option.DefaultHeadlessRule {
+	rule := new(option.DefaultHeadlessRule)
+	count := 0
+
+	for _, domain := range r.ExcludeAttrs.Filter(entry) {
+		ruleVal := strings.TrimSpace(domain.GetValue())
+		if len(ruleVal) == 0 {
+			continue
+		}
+
+		switch domain.Type {
+		case router.Domain_Full:
+			rule.Domain = append(rule.Domain, ruleVal)
+		case router.Domain_RootDomain:
+			rule.DomainSuffix = append(rule.DomainSuffix, ruleVal)
+		case router.Domain_Plain:
+			rule.DomainKeyword = append(rule.DomainKeyword, ruleVal)
+		case router.Domain_Regex:
+			// sing-box fails to load a rule-set with an invalid regular expression
+			if err := lib.ValidateRegex(ruleVal, lib.RegexTargetSingBox); err != nil {
+				slog.Warn("invalid regular expression dropped", "name", entry.GetName(), "regexp", ruleVal, "err", err)
+				continue
+			}
+			rule.DomainRegex = append(rule.DomainRegex, ruleVal)
+		default:
+			continue
+		}
+		count++
+	}
+
+	if count == 0 {
+		return nil
+	}
+	return rule
+}
+
+func (r *RuleSetOut) marshal(rules []option.DefaultHeadlessRule, format string) ([]byte, error) {
+	if format == FormatSource {
+		data, err := json.MarshalIndent(ruleSetSource{Version: r.Version, Rules: rules}, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	}
+
+	ruleSet := option.PlainRuleSet{
+		Rules: make([]option.HeadlessRule, 0, len(rules)),
+	}
+	for _, rule := range rules {
+		ruleSet.Rules = append(ruleSet.Rules, option.HeadlessRule{
+			Type:           C.RuleTypeDefault,
+			DefaultOptions: rule,
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := srs.Write(&buf, ruleSet, r.Version); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
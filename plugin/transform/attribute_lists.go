@@ -0,0 +1,89 @@
+package transform
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/alexxyjiang/domain-list-custom/lib"
+)
+
+const (
+	TypeAttributeLists = "attributeLists"
+	DescAttributeLists = "Generate a NAME@ATTR list for every attribute of a list"
+)
+
+func init() {
+	lib.RegisterTransformConfigCreator(TypeAttributeLists, func(action lib.Action, data json.RawMessage) (lib.TransformConverter, error) {
+		return newAttributeLists(action, data)
+	})
+	lib.RegisterTransformConverter(TypeAttributeLists, &AttributeLists{
+		Type:        TypeAttributeLists,
+		Description: DescAttributeLists,
+	})
+}
+
+type AttributeLists struct {
+	Type        string
+	Action      lib.Action
+	Description string
+	Want        []string
+	Exclude     []string
+}
+
+type attributeListsArgs struct {
+	Want    []string `json:"wantedList" desc:"Lists to generate attribute lists for, all lists if empty"`
+	Exclude []string `json:"excludedList" desc:"Lists to leave untouched"`
+}
+
+func newAttributeLists(action lib.Action, data json.RawMessage) (lib.TransformConverter, error) {
+	var tmp attributeListsArgs
+	if err := lib.UnmarshalArgs(data, &tmp); err != nil {
+		return nil, err
+	}
+
+	if action != lib.ActionTransform {
+		return nil, fmt.Errorf("unsupported action: %s", action)
+	}
+
+	return &AttributeLists{
+		Type:        TypeAttributeLists,
+		Action:      action,
+		Description: DescAttributeLists,
+		Want:        tmp.Want,
+		Exclude:     tmp.Exclude,
+	}, nil
+}
+
+func (a *AttributeLists) GetType() string {
+	return a.Type
+}
+
+func (a *AttributeLists) GetAction() lib.Action {
+	return a.Action
+}
+
+func (a *AttributeLists) GetDescription() string {
+	return a.Description
+}
+
+func (a *AttributeLists) GetArgs() []lib.Arg {
+	return lib.DescribeArgs(attributeListsArgs{})
+}
+
+func (a *AttributeLists) Transform(container lib.Container) (lib.Container, error) {
+	// Collect the attribute lists first, as they are added to the container being iterated
+	attrEntries := make([]*lib.Entry, 0)
+	for _, name := range lib.FilterAndSortList(container, a.Want, a.Exclude) {
+		attrEntries = append(attrEntries, lib.NewAttributeEntries(container, name)...)
+	}
+
+	for _, attrEntry := range attrEntries {
+		if err := container.Add(attrEntry); err != nil {
+			return nil, err
+		}
+		slog.Info("entry generated", "name", attrEntry.GetName(), "domains count", len(attrEntry.GetDomains()))
+	}
+
+	return container, nil
+}
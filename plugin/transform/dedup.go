@@ -0,0 +1,94 @@
+package transform
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/alexxyjiang/domain-list-custom/lib"
+)
+
+const (
+	TypeDedup = "dedup"
+	DescDedup = "Remove duplicated rules, and optionally rules covered by domain rules"
+)
+
+func init() {
+	lib.RegisterTransformConfigCreator(TypeDedup, func(action lib.Action, data json.RawMessage) (lib.TransformConverter, error) {
+		return newDedup(action, data)
+	})
+	lib.RegisterTransformConverter(TypeDedup, &Dedup{
+		Type:        TypeDedup,
+		Description: DescDedup,
+	})
+}
+
+type Dedup struct {
+	Type        string
+	Action      lib.Action
+	Description string
+	Want        []string
+	Exclude     []string
+	Minimize    bool
+}
+
+type dedupArgs struct {
+	Want     []string `json:"wantedList" desc:"Lists to deduplicate, all lists if empty"`
+	Exclude  []string `json:"excludedList" desc:"Lists to leave untouched"`
+	Minimize bool     `json:"minimize" desc:"Also remove rules covered by domain rules with the same attributes"`
+}
+
+func newDedup(action lib.Action, data json.RawMessage) (lib.TransformConverter, error) {
+	var tmp dedupArgs
+	if err := lib.UnmarshalArgs(data, &tmp); err != nil {
+		return nil, err
+	}
+
+	if action != lib.ActionTransform {
+		return nil, fmt.Errorf("unsupported action: %s", action)
+	}
+
+	return &Dedup{
+		Type:        TypeDedup,
+		Action:      action,
+		Description: DescDedup,
+		Want:        tmp.Want,
+		Exclude:     tmp.Exclude,
+		Minimize:    tmp.Minimize,
+	}, nil
+}
+
+func (d *Dedup) GetType() string {
+	return d.Type
+}
+
+func (d *Dedup) GetAction() lib.Action {
+	return d.Action
+}
+
+func (d *Dedup) GetDescription() string {
+	return d.Description
+}
+
+func (d *Dedup) GetArgs() []lib.Arg {
+	return lib.DescribeArgs(dedupArgs{})
+}
+
+func (d *Dedup) Transform(container lib.Container) (lib.Container, error) {
+	total := 0
+	for _, name := range lib.FilterAndSortList(container, d.Want, d.Exclude) {
+		entry, found := container.GetEntry(name)
+		if !found {
+			slog.Debug("❌️ entry not found", "name", name)
+			continue
+		}
+
+		if removed := entry.Deduplicate(d.Minimize); removed > 0 {
+			slog.Info("duplicated rules removed", "name", name, "count", removed)
+			total += removed
+		}
+	}
+	slog.Debug("deduplication completed", "removed", total)
+
+	return container, nil
+}
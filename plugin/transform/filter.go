@@ -0,0 +1,111 @@
+package transform
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/alexxyjiang/domain-list-custom/lib"
+	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
+)
+
+const (
+	TypeFilter = "filter"
+	DescFilter = "Keep the rules of a list matching an attribute expression"
+)
+
+func init() {
+	lib.RegisterTransformConfigCreator(TypeFilter, func(action lib.Action, data json.RawMessage) (lib.TransformConverter, error) {
+		return newFilter(action, data)
+	})
+	lib.RegisterTransformConverter(TypeFilter, &Filter{
+		Type:        TypeFilter,
+		Description: DescFilter,
+	})
+}
+
+type Filter struct {
+	Type        string
+	Action      lib.Action
+	Description string
+	List        string
+	Expr        lib.AttributeExpr
+	Name        string
+}
+
+type filterArgs struct {
+	List string `json:"list" required:"true" desc:"List to filter"`
+	Expr string `json:"expr" required:"true" desc:"Attribute expression of the rules to keep, e.g. @cn && !@ads"`
+	Name string `json:"name" desc:"Name of the resulting list, replacing the existing one, the filtered list if empty"`
+}
+
+func newFilter(action lib.Action, data json.RawMessage) (lib.TransformConverter, error) {
+	var tmp filterArgs
+	if err := lib.UnmarshalArgs(data, &tmp); err != nil {
+		return nil, err
+	}
+
+	if action != lib.ActionTransform {
+		return nil, fmt.Errorf("unsupported action: %s", action)
+	}
+
+	if strings.TrimSpace(tmp.List) == "" {
+		return nil, fmt.Errorf("list is required")
+	}
+
+	expr, err := lib.ParseAttributeExpr(tmp.Expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expr: %w", err)
+	}
+
+	// Filter the list in place by default
+	if strings.TrimSpace(tmp.Name) == "" {
+		tmp.Name = tmp.List
+	}
+
+	return &Filter{
+		Type:        TypeFilter,
+		Action:      action,
+		Description: DescFilter,
+		List:        tmp.List,
+		Expr:        expr,
+		Name:        tmp.Name,
+	}, nil
+}
+
+func (f *Filter) GetType() string {
+	return f.Type
+}
+
+func (f *Filter) GetAction() lib.Action {
+	return f.Action
+}
+
+func (f *Filter) GetDescription() string {
+	return f.Description
+}
+
+func (f *Filter) GetArgs() []lib.Arg {
+	return lib.DescribeArgs(filterArgs{})
+}
+
+func (f *Filter) Transform(container lib.Container) (lib.Container, error) {
+	entry, found := container.GetEntry(f.List)
+	if !found {
+		return nil, fmt.Errorf("entry %s not found", strings.ToUpper(f.List))
+	}
+
+	result := lib.NewEntry(f.Name)
+	result.AddDomains(slices.DeleteFunc(slices.Clone(entry.GetDomains()), func(domain *router.Domain) bool {
+		return !f.Expr.Match(domain)
+	}))
+
+	if err := container.Replace(result); err != nil {
+		return nil, err
+	}
+	slog.Info("entry generated", "name", result.GetName(), "expr", f.Expr.String(), "domains count", len(result.GetDomains()))
+
+	return container, nil
+}
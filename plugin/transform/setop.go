@@ -0,0 +1,135 @@
+package transform
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/alexxyjiang/domain-list-custom/lib"
+)
+
+const (
+	TypeUnion        = "union"
+	TypeIntersection = "intersection"
+	TypeDifference   = "difference"
+
+	DescUnion        = "Write the rules of all lists to a new list"
+	DescIntersection = "Write the rules matching domains matched by all lists to a new list"
+	DescDifference   = "Write the rules of the first list not matched by the other lists to a new list"
+)
+
+func init() {
+	for typ, desc := range map[string]string{
+		TypeUnion:        DescUnion,
+		TypeIntersection: DescIntersection,
+		TypeDifference:   DescDifference,
+	} {
+		lib.RegisterTransformConfigCreator(typ, func(action lib.Action, data json.RawMessage) (lib.TransformConverter, error) {
+			return newSetOperation(typ, desc, action, data)
+		})
+		lib.RegisterTransformConverter(typ, &SetOperation{
+			Type:        typ,
+			Description: desc,
+		})
+	}
+}
+
+// SetOperation writes the result of a set operation on lists to a new list,
+// replacing the list with the same name if any
+type SetOperation struct {
+	Type        string
+	Action      lib.Action
+	Description string
+	Name        string
+	Lists       []string
+}
+
+type setOperationArgs struct {
+	Name  string   `json:"name" required:"true" desc:"Name of the resulting list, replacing the existing one"`
+	Lists []string `json:"lists" required:"true" desc:"Lists to operate on, for difference the first one minus the others"`
+}
+
+func newSetOperation(typ, desc string, action lib.Action, data json.RawMessage) (lib.TransformConverter, error) {
+	var tmp setOperationArgs
+	if err := lib.UnmarshalArgs(data, &tmp); err != nil {
+		return nil, err
+	}
+
+	if action != lib.ActionTransform {
+		return nil, fmt.Errorf("unsupported action: %s", action)
+	}
+
+	if strings.TrimSpace(tmp.Name) == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	lists := make([]string, 0, len(tmp.Lists))
+	for _, list := range tmp.Lists {
+		if list = strings.ToUpper(strings.TrimSpace(list)); list != "" {
+			lists = append(lists, list)
+		}
+	}
+
+	minLists := 2
+	if typ == TypeUnion {
+		minLists = 1
+	}
+	if len(lists) < minLists {
+		return nil, fmt.Errorf("at least %d lists are required", minLists)
+	}
+
+	return &SetOperation{
+		Type:        typ,
+		Action:      action,
+		Description: desc,
+		Name:        tmp.Name,
+		Lists:       lists,
+	}, nil
+}
+
+func (s *SetOperation) GetType() string {
+	return s.Type
+}
+
+func (s *SetOperation) GetAction() lib.Action {
+	return s.Action
+}
+
+func (s *SetOperation) GetDescription() string {
+	return s.Description
+}
+
+func (s *SetOperation) GetArgs() []lib.Arg {
+	return lib.DescribeArgs(setOperationArgs{})
+}
+
+func (s *SetOperation) Transform(container lib.Container) (lib.Container, error) {
+	entries := make([]*lib.Entry, 0, len(s.Lists))
+	for _, name := range s.Lists {
+		entry, found := container.GetEntry(name)
+		if !found {
+			return nil, fmt.Errorf("entry %s not found", name)
+		}
+		entries = append(entries, entry)
+	}
+
+	var result *lib.Entry
+	switch s.Type {
+	case TypeUnion:
+		result = lib.UnionEntries(s.Name, entries...)
+	case TypeIntersection:
+		result = lib.IntersectEntries(s.Name, entries...)
+	case TypeDifference:
+		result = lib.SubtractEntries(s.Name, entries[0], entries[1:]...)
+	default:
+		return nil, fmt.Errorf("unknown set operation: %s", s.Type)
+	}
+
+	if err := container.Replace(result); err != nil {
+		return nil, err
+	}
+	slog.Info("entry generated", "name", result.GetName(), "operation", s.Type, "domains count", len(result.GetDomains()))
+
+	return container, nil
+}
@@ -0,0 +1,43 @@
+package v2ray
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/alexxyjiang/domain-list-custom/lib"
+	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
+	"google.golang.org/protobuf/proto"
+)
+
+// ReadGeoSiteList reads a V2Ray geosite file from a local path
+func ReadGeoSiteList(path string) (*router.GeoSiteList, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read geosite file %s: %w", path, err)
+	}
+
+	geositeList := new(router.GeoSiteList)
+	if err := proto.Unmarshal(data, geositeList); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal geosite file %s: %w", path, err)
+	}
+
+	return geositeList, nil
+}
+
+// NewEntryFromGeoSite converts a list of a V2Ray geosite file to an entry
+func NewEntryFromGeoSite(geosite *router.GeoSite) *lib.Entry {
+	entry := lib.NewEntry(geosite.GetCountryCode())
+	entry.AddDomains(geosite.GetDomain())
+	return entry
+}
+
+// NewContainerFromGeoSiteList converts all lists of a V2Ray geosite file to a container
+func NewContainerFromGeoSiteList(geositeList *router.GeoSiteList) (lib.Container, error) {
+	container := lib.NewSimpleContainer()
+	for _, geosite := range geositeList.GetEntry() {
+		if err := container.Add(NewEntryFromGeoSite(geosite)); err != nil {
+			return nil, err
+		}
+	}
+	return container, nil
+}
@@ -0,0 +1,137 @@
+package v2ray
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/alexxyjiang/domain-list-custom/lib"
+)
+
+const (
+	TypeGeositeIn = "v2rayGeoSite"
+	DescGeositeIn = "Convert V2Ray geosite format to other formats"
+)
+
+func init() {
+	lib.RegisterInputConfigCreator(TypeGeositeIn, func(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
+		return newGeositeIn(action, data)
+	})
+	lib.RegisterInputConverter(TypeGeositeIn, &GeositeIn{
+		Type:        TypeGeositeIn,
+		Description: DescGeositeIn,
+	})
+}
+
+type GeositeIn struct {
+	Type        string
+	Action      lib.Action
+	Description string
+	InputFile   string
+	Want        map[string]bool
+	RemoveCase  lib.CaseRemove
+	Naming      *lib.EntryNaming
+}
+
+type geositeInArgs struct {
+	InputFile   string   `json:"inputFile" required:"true" desc:"Path of the geosite file"`
+	Want        []string `json:"wantedList" desc:"Lists to load, all lists if empty"`
+	RemoveEntry bool     `json:"removeEntry" desc:"Remove whole lists instead of their rules with the remove action"`
+	lib.NamingArgs
+}
+
+func newGeositeIn(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
+	var tmp geositeInArgs
+	if err := lib.UnmarshalArgs(data, &tmp); err != nil {
+		return nil, err
+	}
+
+	if action != lib.ActionAdd && action != lib.ActionRemove {
+		return nil, fmt.Errorf("unsupported action: %s", action)
+	}
+
+	removeCase := lib.CaseRemovePrefix
+	if tmp.RemoveEntry {
+		removeCase = lib.CaseRemoveEntry
+	}
+
+	// Filter wanted list
+	wantList := make(map[string]bool)
+	for _, want := range tmp.Want {
+		if want = strings.ToUpper(strings.TrimSpace(want)); want != "" {
+			wantList[want] = true
+		}
+	}
+
+	naming, err := tmp.NamingArgs.EntryNaming()
+	if err != nil {
+		return nil, err
+	}
+
+	return &GeositeIn{
+		Type:        TypeGeositeIn,
+		Action:      action,
+		Description: DescGeositeIn,
+		InputFile:   tmp.InputFile,
+		Want:        wantList,
+		RemoveCase:  removeCase,
+		Naming:      naming,
+	}, nil
+}
+
+func (g *GeositeIn) GetType() string {
+	return g.Type
+}
+
+func (g *GeositeIn) GetAction() lib.Action {
+	return g.Action
+}
+
+func (g *GeositeIn) GetDescription() string {
+	return g.Description
+}
+
+func (g *GeositeIn) GetArgs() []lib.Arg {
+	return lib.DescribeArgs(geositeInArgs{})
+}
+
+func (g *GeositeIn) Input(container lib.Container) (lib.Container, error) {
+	geositeList, err := ReadGeoSiteList(g.InputFile)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, geosite := range geositeList.GetEntry() {
+		name := strings.ToUpper(strings.TrimSpace(geosite.GetCountryCode()))
+
+		// Filter by wanted list if specified
+		if len(g.Want) > 0 && !g.Want[name] {
+			continue
+		}
+
+		// Attributes are kept as is, so that they can be written to and read back from the text format
+		for _, domain := range geosite.GetDomain() {
+			for _, attr := range domain.GetAttribute() {
+				if !lib.IsAttributeKey(attr.GetKey()) {
+					return nil, fmt.Errorf("invalid attribute %q of rule %s in list %s", attr.GetKey(), lib.FormatDomain(domain), name)
+				}
+			}
+		}
+
+		entry := lib.NewEntry(name)
+		entry.AddDomains(geosite.GetDomain())
+
+		switch g.Action {
+		case lib.ActionAdd:
+			if err := g.Naming.Add(container, entry); err != nil {
+				return nil, err
+			}
+		case lib.ActionRemove:
+			if err := g.Naming.Remove(container, entry, g.RemoveCase); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return container, nil
+}
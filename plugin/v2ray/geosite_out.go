@@ -1,17 +1,16 @@
 package v2ray
 
 import (
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os"
 	"path/filepath"
-	"slices"
 	"strings"
 	"time"
 
 	"github.com/alexxyjiang/domain-list-custom/lib"
+	"github.com/alexxyjiang/domain-list-custom/plugin/plaintext"
 	router "github.com/v2fly/v2ray-core/v5/app/router/routercommon"
 	"google.golang.org/protobuf/proto"
 )
@@ -26,74 +25,61 @@ func init() {
 		return newGeositeOut(action, data)
 	})
 	lib.RegisterOutputConverter(TypeGeositeOut, &GeositeOut{
+		Type:        TypeGeositeOut,
 		Description: DescGeositeOut,
 	})
 }
 
 type GeositeOut struct {
-	Type          string
-	Action        lib.Action
-	Description   string
-	OutputDir     string
-	OutputName    string
-	Want          []string
-	Exclude       []string
-	ExcludeAttrs  map[string]map[string]bool
-	GFWListOutput string
+	Type           string
+	Action         lib.Action
+	Description    string
+	OutputDir      string
+	OutputName     string
+	Want           []string
+	Exclude        []string
+	ExcludeAttrs   lib.ExcludeAttrs
+	AttributeLists bool
+	GFWListOutput  string
 }
 
-func newGeositeOut(action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
-	var tmp struct {
-		OutputDir     string   `json:"outputDir"`
-		OutputName    string   `json:"outputName"`
-		Want          []string `json:"wantedList"`
-		Exclude       []string `json:"excludedList"`
-		ExcludeAttrs  string   `json:"excludeAttrs"`
-		GFWListOutput string   `json:"gfwlistOutput"`
-	}
-
-	if len(data) > 0 {
-		if err := json.Unmarshal(data, &tmp); err != nil {
-			return nil, err
-		}
-	}
+type geositeOutArgs struct {
+	OutputDir      string   `json:"outputDir" default:"./output" desc:"Output directory"`
+	OutputName     string   `json:"outputName" default:"geosite.dat" desc:"Output file name"`
+	Want           []string `json:"wantedList" desc:"Lists to export, all lists if empty"`
+	Exclude        []string `json:"excludedList" desc:"Lists to exclude"`
+	ExcludeAttrs   string   `json:"excludeAttrs" desc:"Attributes of the rules to exclude from lists, e.g. cn@!cn@ads,geolocation-cn@!cn"`
+	AttributeLists bool     `json:"attributeLists" desc:"Also generate a NAME@ATTR list for every attribute of a list, for consumers other than V2Ray"`
+	GFWListOutput  string   `json:"gfwlistOutput" desc:"Deprecated, list to generate as gfwlist.txt, use the gfwlist output instead"`
+}
 
-	if tmp.OutputDir == "" {
-		tmp.OutputDir = "./output"
+func newGeositeOut(action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
+	var tmp geositeOutArgs
+	if err := lib.UnmarshalArgs(data, &tmp); err != nil {
+		return nil, err
 	}
 
-	if tmp.OutputName == "" {
-		tmp.OutputName = "geosite.dat"
+	if tmp.GFWListOutput != "" {
+		slog.Warn("gfwlistOutput is deprecated, use the gfwlist output instead")
 	}
 
 	// Process exclude attributes
-	excludeAttrsMap := make(map[string]map[string]bool)
-	if tmp.ExcludeAttrs != "" {
-		exFilenameAttrSlice := strings.Split(tmp.ExcludeAttrs, ",")
-		for _, exFilenameAttr := range exFilenameAttrSlice {
-			exFilenameAttr = strings.TrimSpace(exFilenameAttr)
-			exFilenameAttrMap := strings.Split(exFilenameAttr, "@")
-			filename := strings.ToUpper(strings.TrimSpace(exFilenameAttrMap[0]))
-			excludeAttrsMap[filename] = make(map[string]bool)
-			for _, attr := range exFilenameAttrMap[1:] {
-				attr = strings.TrimSpace(attr)
-				if len(attr) > 0 {
-					excludeAttrsMap[filename][attr] = true
-				}
-			}
-		}
+	excludeAttrs, err := lib.ParseExcludeAttrs(tmp.ExcludeAttrs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid excludeAttrs: %w", err)
 	}
 
 	return &GeositeOut{
-		Type:          TypeGeositeOut,
-		Action:        action,
-		Description:   DescGeositeOut,
-		OutputDir:     tmp.OutputDir,
-		OutputName:    tmp.OutputName,
-		Want:          tmp.Want,
-		Exclude:       tmp.Exclude,
-		ExcludeAttrs:  excludeAttrsMap,
-		GFWListOutput: tmp.GFWListOutput,
+		Type:           TypeGeositeOut,
+		Action:         action,
+		Description:    DescGeositeOut,
+		OutputDir:      tmp.OutputDir,
+		OutputName:     tmp.OutputName,
+		Want:           tmp.Want,
+		Exclude:        tmp.Exclude,
+		ExcludeAttrs:   excludeAttrs,
+		AttributeLists: tmp.AttributeLists,
+		GFWListOutput:  tmp.GFWListOutput,
 	}, nil
 }
 
@@ -109,6 +95,10 @@ func (g *GeositeOut) GetDescription() string {
 	return g.Description
 }
 
+func (g *GeositeOut) GetArgs() []lib.Arg {
+	return lib.DescribeArgs(geositeOutArgs{})
+}
+
 func (g *GeositeOut) Output(container lib.Container) error {
 	// Create output directory
 	if err := os.MkdirAll(g.OutputDir, 0755); err != nil {
@@ -116,7 +106,7 @@ func (g *GeositeOut) Output(container lib.Container) error {
 	}
 
 	// Generate geosite list
-	geositeList := g.toGeoSiteList(container)
+	geositeList := g.ToGeoSiteList(container)
 	if geositeList == nil {
 		return fmt.Errorf("failed to generate geosite list")
 	}
@@ -124,7 +114,7 @@ func (g *GeositeOut) Output(container lib.Container) error {
 	slog.Debug("geosite out", "geositeList", geositeList)
 
 	// Marshal to protobuf
-	protoBytes, err := proto.Marshal(geositeList)
+	protoBytes, err := proto.MarshalOptions{Deterministic: true}.Marshal(geositeList)
 	if err != nil {
 		return fmt.Errorf("failed to marshal geosite list: %w", err)
 	}
@@ -147,10 +137,12 @@ func (g *GeositeOut) Output(container lib.Container) error {
 	return nil
 }
 
-func (g *GeositeOut) toGeoSiteList(container lib.Container) *router.GeoSiteList {
+// ToGeoSiteList converts the wanted entries of the container to a geosite list,
+// excluding the domains with the configured attributes
+func (g *GeositeOut) ToGeoSiteList(container lib.Container) *router.GeoSiteList {
 	geositeList := new(router.GeoSiteList)
 
-	for _, name := range g.filterAndSortList(container) {
+	for _, name := range lib.FilterAndSortList(container, g.Want, g.Exclude) {
 		entry, found := container.GetEntry(name)
 		if !found {
 			slog.Debug("❌️ entry not found", "name", name)
@@ -161,6 +153,15 @@ func (g *GeositeOut) toGeoSiteList(container lib.Container) *router.GeoSiteList
 		if geosite != nil {
 			geositeList.Entry = append(geositeList.Entry, geosite)
 		}
+
+		if g.AttributeLists {
+			for _, attrEntry := range lib.NewAttributeEntries(container, name) {
+				// Skip the attributes of excluded domains only
+				if attrGeosite := g.toGeoSite(attrEntry); len(attrGeosite.GetDomain()) > 0 {
+					geositeList.Entry = append(geositeList.Entry, attrGeosite)
+				}
+			}
+		}
 	}
 
 	return geositeList
@@ -171,117 +172,34 @@ func (g *GeositeOut) toGeoSite(entry *lib.Entry) *router.GeoSite {
 	geosite.CountryCode = strings.ToUpper(entry.GetName())
 
 	// Filter domains based on exclude attributes
-	excludeAttrsMap := g.ExcludeAttrs[entry.GetName()]
-
-	for _, domain := range entry.GetDomains() {
-		// Check if domain should be excluded based on attributes
-		if excludeAttrsMap != nil && len(domain.GetAttribute()) > 0 {
-			shouldExclude := false
-			for _, attr := range domain.GetAttribute() {
-				if excludeAttrsMap[attr.GetKey()] {
-					shouldExclude = true
-					break
-				}
-			}
-			if shouldExclude {
-				continue
-			}
-		}
-
-		geosite.Domain = append(geosite.Domain, domain)
-	}
+	geosite.Domain = g.ExcludeAttrs.Filter(entry)
 
 	return geosite
 }
 
+// generateGFWList generates the GFWList with the legacy header of the deprecated gfwlistOutput argument
 func (g *GeositeOut) generateGFWList(container lib.Container) error {
-	// Find the entry for GFWList
-	listName := strings.ToUpper(g.GFWListOutput)
-	entry, found := container.GetEntry(listName)
-	if !found {
-		return fmt.Errorf("entry %s not found for GFWList generation", listName)
-	}
-
-	// Generate GFWList content
-	loc, _ := time.LoadLocation("Asia/Shanghai")
-	timeString := fmt.Sprintf("! Last Modified: %s\n", time.Now().In(loc).Format(time.RFC1123))
-
-	gfwlistBytes := make([]byte, 0, 1024*512)
-	gfwlistBytes = append(gfwlistBytes, []byte("[AutoProxy 0.2.9]\n")...)
-	gfwlistBytes = append(gfwlistBytes, []byte(timeString)...)
-	gfwlistBytes = append(gfwlistBytes, []byte("! Expires: 24h\n")...)
-	gfwlistBytes = append(gfwlistBytes, []byte("! HomePage: https://github.com/Loyalsoldier/domain-list-custom\n")...)
-	gfwlistBytes = append(gfwlistBytes, []byte("! GitHub URL: https://raw.githubusercontent.com/Loyalsoldier/domain-list-custom/release/gfwlist.txt\n")...)
-	gfwlistBytes = append(gfwlistBytes, []byte("! jsdelivr URL: https://cdn.jsdelivr.net/gh/Loyalsoldier/domain-list-custom@release/gfwlist.txt\n")...)
-	gfwlistBytes = append(gfwlistBytes, []byte("\n")...)
-
-	for _, domain := range entry.GetDomains() {
-		ruleVal := strings.TrimSpace(domain.GetValue())
-		if len(ruleVal) == 0 {
-			continue
-		}
-
-		switch domain.Type {
-		case router.Domain_Full:
-			gfwlistBytes = append(gfwlistBytes, []byte("|http://"+ruleVal+"\n")...)
-			gfwlistBytes = append(gfwlistBytes, []byte("|https://"+ruleVal+"\n")...)
-		case router.Domain_RootDomain:
-			gfwlistBytes = append(gfwlistBytes, []byte("||"+ruleVal+"\n")...)
-		case router.Domain_Plain:
-			gfwlistBytes = append(gfwlistBytes, []byte(ruleVal+"\n")...)
-		case router.Domain_Regex:
-			gfwlistBytes = append(gfwlistBytes, []byte("/"+ruleVal+"/\n")...)
-		}
-	}
-
-	// Encode to base64 and write to file
-	filepath := filepath.Join(g.OutputDir, "gfwlist.txt")
-	f, err := os.OpenFile(filepath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	loc, err := time.LoadLocation("Asia/Shanghai")
 	if err != nil {
-		return fmt.Errorf("failed to create gfwlist file: %w", err)
-	}
-	defer f.Close()
-
-	encoder := base64.NewEncoder(base64.StdEncoding, f)
-	defer encoder.Close()
-
-	if _, err := encoder.Write(gfwlistBytes); err != nil {
-		return fmt.Errorf("failed to write gfwlist: %w", err)
-	}
-
-	slog.Info("✅ file gfwlist.txt generated")
-	return nil
-}
-
-func (g *GeositeOut) filterAndSortList(container lib.Container) []string {
-	excludeMap := make(map[string]bool)
-	for _, exclude := range g.Exclude {
-		if exclude = strings.ToUpper(strings.TrimSpace(exclude)); exclude != "" {
-			excludeMap[exclude] = true
-		}
-	}
-
-	wantList := make([]string, 0, len(g.Want))
-	for _, want := range g.Want {
-		if want = strings.ToUpper(strings.TrimSpace(want)); want != "" && !excludeMap[want] {
-			wantList = append(wantList, want)
-		}
-	}
-
-	if len(wantList) > 0 {
-		slices.Sort(wantList)
-		return wantList
-	}
-
-	list := make([]string, 0, 300)
-	for entry := range container.Loop() {
-		name := entry.GetName()
-		if excludeMap[name] {
-			continue
-		}
-		list = append(list, name)
-	}
-
-	slices.Sort(list)
-	return list
+		loc = time.UTC
+	}
+
+	gfwlistOut := &plaintext.GFWListOut{
+		Type:        plaintext.TypeGFWListOut,
+		Action:      lib.ActionOutput,
+		Description: plaintext.DescGFWListOut,
+		OutputDir:   g.OutputDir,
+		OutputName:  "gfwlist.txt",
+		List:        g.GFWListOutput,
+		Header: []string{
+			"Expires: 24h",
+			"HomePage: https://github.com/Loyalsoldier/domain-list-custom",
+			"GitHub URL: https://raw.githubusercontent.com/Loyalsoldier/domain-list-custom/release/gfwlist.txt",
+			"jsdelivr URL: https://cdn.jsdelivr.net/gh/Loyalsoldier/domain-list-custom@release/gfwlist.txt",
+		},
+		Location: loc,
+		Base64:   true,
+	}
+
+	return gfwlistOut.Output(container)
 }
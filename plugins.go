@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/alexxyjiang/domain-list-custom/lib"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(pluginsCmd)
+	pluginsCmd.PersistentFlags().StringP("format", "f", "text", "Output format of the plugins, text or json")
+}
+
+var pluginsCmd = &cobra.Command{
+	Use:     "plugins [TYPE...]",
+	Aliases: []string{"plugin"},
+	Short:   "List the available input, transform and output plugins with their actions and arguments",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		format = strings.ToLower(strings.TrimSpace(format))
+		if format != "text" && format != "json" {
+			return fmt.Errorf("unknown output format: %s", format)
+		}
+
+		inputs := filterPlugins(lib.ListInputConverters(), args)
+		transforms := filterPlugins(lib.ListTransformConverters(), args)
+		outputs := filterPlugins(lib.ListOutputConverters(), args)
+
+		if format == "json" {
+			data, err := json.MarshalIndent(map[string][]*lib.PluginInfo{
+				"input":     inputs,
+				"transform": transforms,
+				"output":    outputs,
+			}, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to marshal plugins: %w", err)
+			}
+			fmt.Println(string(data))
+			return nil
+		}
+
+		fmt.Println("Input plugins:", len(inputs), "in total")
+		printPlugins(inputs)
+		fmt.Println("Transform plugins:", len(transforms), "in total")
+		printPlugins(transforms)
+		fmt.Println("Output plugins:", len(outputs), "in total")
+		printPlugins(outputs)
+		return nil
+	},
+}
+
+// filterPlugins returns the plugins of the wanted types, or all plugins if types is empty
+func filterPlugins(infos []*lib.PluginInfo, types []string) []*lib.PluginInfo {
+	if len(types) == 0 {
+		return infos
+	}
+
+	filtered := make([]*lib.PluginInfo, 0, len(types))
+	for _, info := range infos {
+		for _, typ := range types {
+			if strings.EqualFold(info.Type, strings.TrimSpace(typ)) {
+				filtered = append(filtered, info)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+func printPlugins(infos []*lib.PluginInfo) {
+	for _, info := range infos {
+		actions := make([]string, 0, len(info.Actions))
+		for _, action := range info.Actions {
+			actions = append(actions, string(action))
+		}
+
+		fmt.Println("---")
+		fmt.Println(info.Type, "-", info.Description)
+		fmt.Println("  actions:", strings.Join(actions, ", "))
+		if len(info.Args) == 0 {
+			continue
+		}
+
+		fmt.Println("  args:")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, arg := range info.Args {
+			required := "optional"
+			if arg.Required {
+				required = "required"
+			}
+			description := arg.Description
+			if arg.Default != "" {
+				description += " (default: " + arg.Default + ")"
+			}
+			fmt.Fprintf(w, "    %s\t%s\t%s\t%s\n", arg.Name, arg.Type, required, description)
+		}
+		w.Flush()
+	}
+}